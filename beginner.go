@@ -4,12 +4,21 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"log/slog"
+
+	"github.com/amidgo/tx/internal/sqlctx"
 )
 
 var (
-	ErrSerialization = errors.New("serialization error")
-	ErrCommit        = errors.New("commit error")
-	ErrBeginTx       = errors.New("begin tx error")
+	ErrSerialization   = errors.New("serialization error")
+	ErrCommit          = errors.New("commit error")
+	ErrBeginTx         = errors.New("begin tx error")
+	ErrUniqueViolation = errors.New("unique violation")
+	// ErrConnection is a lost or failed database connection, classified by Driver.
+	ErrConnection = errors.New("connection error")
+	// ErrAmbiguousTx is returned by helpers working with transaction of ctx without knowing its database,
+	// e.g. outbox.Publish or ExecVersioned, when ctx holds transactions of several databases.
+	ErrAmbiguousTx = sqlctx.ErrAmbiguousTx
 )
 
 type Tx interface {
@@ -25,6 +34,10 @@ func (contextWrapper) Context() context.Context {
 	return context.Background()
 }
 
+func (c contextWrapper) State() TxState {
+	return State(c.CommitRollbacker)
+}
+
 type CommitRollbacker interface {
 	Commit() error
 	Rollback() error
@@ -35,6 +48,29 @@ type Beginner interface {
 	BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error)
 }
 
+// BeginnerOptions are common options of the adapters beginners.
+type BeginnerOptions struct {
+	NestedBegin       NestedBeginPolicy
+	NestedBeginLogger *slog.Logger
+	TrackTransactions bool
+}
+
+type BeginnerOption func(*BeginnerOptions)
+
+func ApplyBeginnerOptions(opts ...BeginnerOption) BeginnerOptions {
+	options := BeginnerOptions{}
+
+	for _, op := range opts {
+		op(&options)
+	}
+
+	if options.NestedBeginLogger == nil {
+		options.NestedBeginLogger = slog.Default()
+	}
+
+	return options
+}
+
 type Driver interface {
 	Error(err error) error
 }
@@ -48,6 +84,17 @@ func getDriver(x any) (Driver, bool) {
 	return driver.Driver(), true
 }
 
+func txEnabled(ctx context.Context, x any) bool {
+	enabled, ok := x.(interface {
+		TxEnabled(ctx context.Context) bool
+	})
+	if !ok {
+		return false
+	}
+
+	return enabled.TxEnabled(ctx)
+}
+
 type driverBeginner struct {
 	Beginner
 	driver Driver
@@ -62,10 +109,26 @@ func (d driverTx) Driver() Driver {
 	return d.driver
 }
 
+func (d driverTx) State() TxState {
+	return State(d.Tx)
+}
+
 func (d driverBeginner) Driver() Driver {
 	return d.driver
 }
 
+func (d driverBeginner) TxEnabled(ctx context.Context) bool {
+	return txEnabled(ctx, d.Beginner)
+}
+
+func (d driverBeginner) RetryBudget() *RetryBudget {
+	return getRetryBudget(d.Beginner)
+}
+
+func (d driverBeginner) tracker() (runTracker, bool) {
+	return getRunTracker(d.Beginner)
+}
+
 func (d driverBeginner) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
 	tx, err := d.Beginner.BeginTx(ctx, opts)
 
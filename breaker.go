@@ -0,0 +1,266 @@
+package tx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+const defaultBreakerOpenTimeout = 5 * time.Second
+
+type BreakerState int
+
+const (
+	// BreakerClosed passes all begins to the wrapped beginner.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen fails begins with ErrCircuitOpen until open timeout passes.
+	BreakerOpen
+	// BreakerHalfOpen passes single probe begin, others fail with ErrCircuitOpen.
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+type BreakerOption func(*BreakerBeginner)
+
+// WithOpenTimeout sets duration breaker stays open before probing, 5 seconds by default.
+func WithOpenTimeout(timeout time.Duration) BreakerOption {
+	return func(b *BreakerBeginner) {
+		b.openTimeout = timeout
+	}
+}
+
+var _ Beginner = (*BreakerBeginner)(nil)
+
+// BreakerBeginner opens after threshold consecutive failures of the wrapped beginner,
+// failures are begin errors and commit or rollback errors classified as ErrConnection by Driver,
+// transaction counts at most one failure, successful commit or rollback resets the count.
+// Begin errors caused by cancellation of ctx are not failures.
+type BreakerBeginner struct {
+	beginner    Beginner
+	threshold   int
+	openTimeout time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+}
+
+func NewBreakerBeginner(beginner Beginner, threshold int, opts ...BreakerOption) *BreakerBeginner {
+	b := &BreakerBeginner{
+		beginner:    beginner,
+		threshold:   threshold,
+		openTimeout: defaultBreakerOpenTimeout,
+	}
+
+	for _, op := range opts {
+		op(b)
+	}
+
+	return b
+}
+
+func (b *BreakerBeginner) Begin(ctx context.Context) (Tx, error) {
+	return b.begin(ctx, b.beginner.Begin)
+}
+
+func (b *BreakerBeginner) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	return b.begin(ctx, func(ctx context.Context) (Tx, error) {
+		return b.beginner.BeginTx(ctx, opts)
+	})
+}
+
+func (b *BreakerBeginner) begin(ctx context.Context, begin func(ctx context.Context) (Tx, error)) (Tx, error) {
+	probe, err := b.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := begin(ctx)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.Canceled) {
+			b.cancelProbe(probe)
+
+			return nil, err
+		}
+
+		b.failure()
+
+		return nil, err
+	}
+
+	if probe {
+		b.close()
+	}
+
+	driver, _ := getDriver(b.beginner)
+
+	return &breakerTx{Tx: tx, breaker: b, driver: driver}, nil
+}
+
+func (b *BreakerBeginner) BreakerState() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+func (b *BreakerBeginner) Driver() Driver {
+	driver, _ := getDriver(b.beginner)
+
+	return driver
+}
+
+func (b *BreakerBeginner) RetryBudget() *RetryBudget {
+	return getRetryBudget(b.beginner)
+}
+
+func (b *BreakerBeginner) TxEnabled(ctx context.Context) bool {
+	return txEnabled(ctx, b.beginner)
+}
+
+func (b *BreakerBeginner) tracker() (runTracker, bool) {
+	return getRunTracker(b.beginner)
+}
+
+// allow reports whether begin may be called and whether it is the half-open probe.
+func (b *BreakerBeginner) allow() (probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.openTimeout {
+			return false, ErrCircuitOpen
+		}
+
+		b.state = BreakerHalfOpen
+
+		return true, nil
+	case BreakerHalfOpen:
+		return false, ErrCircuitOpen
+	default:
+		return false, nil
+	}
+}
+
+func (b *BreakerBeginner) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerHalfOpen:
+		b.open()
+	case BreakerClosed:
+		b.failures++
+
+		if b.failures >= b.threshold {
+			b.open()
+		}
+	}
+}
+
+// success resets failures of closed breaker, only probe closes not closed breaker.
+func (b *BreakerBeginner) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerClosed {
+		b.failures = 0
+	}
+}
+
+func (b *BreakerBeginner) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = BreakerClosed
+	b.failures = 0
+}
+
+// cancelProbe returns breaker to open state without restarting open timeout, so next begin probes again.
+func (b *BreakerBeginner) cancelProbe(probe bool) {
+	if !probe {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = BreakerOpen
+}
+
+func (b *BreakerBeginner) open() {
+	b.state = BreakerOpen
+	b.failures = 0
+	b.openedAt = time.Now()
+}
+
+type breakerTx struct {
+	Tx
+	breaker  *BreakerBeginner
+	driver   Driver
+	reported atomic.Bool
+}
+
+func (b *breakerTx) Commit() error {
+	err := b.Tx.Commit()
+	b.report(err)
+
+	return err
+}
+
+func (b *breakerTx) Rollback() error {
+	err := b.Tx.Rollback()
+	b.report(err)
+
+	return err
+}
+
+// report counts outcome of the transaction once, by the first commit or rollback that succeeded
+// or failed with connection error, rollback after failed commit isn't counted again.
+func (b *breakerTx) report(err error) {
+	connection := err != nil && errors.Is(driverError(b.driver, err), ErrConnection)
+	if err != nil && !connection {
+		return
+	}
+
+	if !b.reported.CompareAndSwap(false, true) {
+		return
+	}
+
+	if connection {
+		b.breaker.failure()
+
+		return
+	}
+
+	b.breaker.success()
+}
+
+func (b *breakerTx) State() TxState {
+	return State(b.Tx)
+}
+
+func (b *breakerTx) Driver() Driver {
+	driver, _ := getDriver(b.Tx)
+
+	return driver
+}
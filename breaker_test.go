@@ -0,0 +1,217 @@
+package tx_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/amidgo/tx"
+	txmocks "github.com/amidgo/tx/mocks"
+)
+
+func Test_BreakerBeginner(t *testing.T) {
+	ctx := context.Background()
+
+	breaker := tx.NewBreakerBeginner(
+		txmocks.JoinBeginners(
+			txmocks.ExpectBeginTxAndReturnError(io.ErrUnexpectedEOF, nil),
+			txmocks.ExpectBeginTxAndReturnError(io.ErrUnexpectedEOF, nil),
+			txmocks.ExpectBeginTxAndReturnError(io.ErrUnexpectedEOF, nil),
+			txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil),
+			txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil),
+		)(t),
+		2,
+		tx.WithOpenTimeout(20*time.Millisecond),
+	)
+
+	for range 2 {
+		_, err := breaker.BeginTx(ctx, nil)
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("unexpected begin error, %+v", err)
+		}
+	}
+
+	assertBreakerState(t, breaker, tx.BreakerOpen)
+
+	_, err := breaker.BeginTx(ctx, nil)
+	if !errors.Is(err, tx.ErrCircuitOpen) {
+		t.Fatalf("expected circuit open, actual %+v", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	// failed probe opens breaker again
+	_, err = breaker.BeginTx(ctx, nil)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("unexpected probe error, %+v", err)
+	}
+
+	assertBreakerState(t, breaker, tx.BreakerOpen)
+
+	time.Sleep(30 * time.Millisecond)
+
+	probe, err := breaker.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("unexpected probe error, %+v", err)
+	}
+
+	assertBreakerState(t, breaker, tx.BreakerClosed)
+
+	err = probe.Commit()
+	if err != nil {
+		t.Fatalf("unexpected commit error, %+v", err)
+	}
+
+	err = tx.Run(ctx, breaker, func(context.Context) error { return nil }, nil)
+	if err != nil {
+		t.Fatalf("unexpected run error, %+v", err)
+	}
+}
+
+// blockingBeginner blocks begins after the first pass ones until release.
+type blockingBeginner struct {
+	tx.Beginner
+	pass    int
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingBeginner) BeginTx(ctx context.Context, opts *sql.TxOptions) (tx.Tx, error) {
+	if b.pass > 0 {
+		b.pass--
+
+		return b.Beginner.BeginTx(ctx, opts)
+	}
+
+	close(b.started)
+	<-b.release
+
+	return b.Beginner.BeginTx(ctx, opts)
+}
+
+func Test_BreakerBeginner_HalfOpen(t *testing.T) {
+	ctx := context.Background()
+
+	blocking := &blockingBeginner{
+		Beginner: txmocks.JoinBeginners(
+			txmocks.ExpectBeginTxAndReturnError(io.ErrUnexpectedEOF, nil),
+			txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil),
+		)(t),
+		pass:    1,
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+
+	breaker := tx.NewBreakerBeginner(blocking, 1, tx.WithOpenTimeout(time.Millisecond))
+
+	_, err := breaker.BeginTx(ctx, nil)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("unexpected begin error, %+v", err)
+	}
+
+	assertBreakerState(t, breaker, tx.BreakerOpen)
+
+	time.Sleep(5 * time.Millisecond)
+
+	probed := make(chan error)
+
+	go func() {
+		probed <- tx.Run(ctx, breaker, func(context.Context) error { return nil }, nil)
+	}()
+
+	<-blocking.started
+
+	assertBreakerState(t, breaker, tx.BreakerHalfOpen)
+
+	_, err = breaker.BeginTx(ctx, nil)
+	if !errors.Is(err, tx.ErrCircuitOpen) {
+		t.Fatalf("expected circuit open while probing, actual %+v", err)
+	}
+
+	close(blocking.release)
+
+	err = <-probed
+	if err != nil {
+		t.Fatalf("unexpected probe error, %+v", err)
+	}
+
+	assertBreakerState(t, breaker, tx.BreakerClosed)
+}
+
+func Test_BreakerBeginner_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	breaker := tx.NewBreakerBeginner(
+		txmocks.ExpectBeginTxAndReturnError(context.Canceled, nil)(t),
+		1,
+	)
+
+	err := tx.Run(ctx, breaker, func(context.Context) error { return nil }, nil)
+	if !errors.Is(err, tx.ErrBeginTx) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected error, %+v", err)
+	}
+
+	assertBreakerState(t, breaker, tx.BreakerClosed)
+}
+
+func Test_BreakerBeginner_Driver(t *testing.T) {
+	breaker := tx.NewBreakerBeginner(
+		tx.BeginnerWithDriver(
+			txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollback(nil), nil)(t),
+			txmocks.ExpectDriverError(errors.Is, io.ErrUnexpectedEOF, errors.Join(tx.ErrSerialization, io.ErrUnexpectedEOF))(t),
+		),
+		1,
+	)
+
+	err := tx.Run(context.Background(), breaker, func(context.Context) error { return io.ErrUnexpectedEOF }, nil)
+	if !errors.Is(err, tx.ErrSerialization) {
+		t.Fatalf("expected error classified by wrapped driver, actual %+v", err)
+	}
+
+	assertBreakerState(t, breaker, tx.BreakerClosed)
+}
+
+func Test_BreakerBeginner_FailurePerTx(t *testing.T) {
+	connectionDriver := txmocks.ExpectDriverError(errors.Is, io.ErrUnexpectedEOF, errors.Join(tx.ErrConnection, io.ErrUnexpectedEOF))
+
+	breaker := tx.NewBreakerBeginner(
+		tx.BeginnerWithDriver(
+			txmocks.JoinBeginners(
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollback(io.ErrUnexpectedEOF), nil),
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollbackAfterFailedCommit(io.ErrUnexpectedEOF), nil),
+			)(t),
+			// withTx, rollback and breaker of the first transaction, commit and breaker of the second one
+			txmocks.JoinDrivers(connectionDriver, connectionDriver, connectionDriver, connectionDriver, connectionDriver)(t),
+		),
+		2,
+	)
+
+	// lost connection fails both withTx and rollback, counted once
+	err := tx.Run(context.Background(), breaker, func(context.Context) error { return io.ErrUnexpectedEOF }, nil)
+	if !errors.Is(err, tx.ErrConnection) {
+		t.Fatalf("unexpected run error, %+v", err)
+	}
+
+	assertBreakerState(t, breaker, tx.BreakerClosed)
+
+	// failed commit is followed by rollback, counted once
+	err = tx.Run(context.Background(), breaker, func(context.Context) error { return nil }, nil)
+	if !errors.Is(err, tx.ErrCommit) || !errors.Is(err, tx.ErrConnection) {
+		t.Fatalf("unexpected run error, %+v", err)
+	}
+
+	assertBreakerState(t, breaker, tx.BreakerOpen)
+}
+
+func assertBreakerState(t *testing.T, breaker *tx.BreakerBeginner, expected tx.BreakerState) {
+	t.Helper()
+
+	state := breaker.BreakerState()
+	if state != expected {
+		t.Fatalf("unexpected breaker state, expected %s, actual %s", expected, state)
+	}
+}
@@ -0,0 +1,151 @@
+package tx
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+
+// RetryBudget is a token bucket shared by transactions to cap retries to a ratio of total traffic.
+// Every Run deposits ratio of token, every retry withdraws one token,
+// retry fails fast with ErrRetryBudgetExhausted when there is no whole token left.
+// RetryBudget is safe for concurrent use.
+type RetryBudget struct {
+	mu sync.Mutex
+
+	ratio     float64
+	maxTokens float64
+	tokens    float64
+
+	requests  uint64
+	retries   uint64
+	exhausted uint64
+}
+
+// NewRetryBudget creates budget allowing retries of ratio of runs, e.g. 0.1 is 10%,
+// maxTokens caps burst of retries, the bucket starts full.
+func NewRetryBudget(ratio float64, maxTokens int) *RetryBudget {
+	return &RetryBudget{
+		ratio:     ratio,
+		maxTokens: float64(maxTokens),
+		tokens:    float64(maxTokens),
+	}
+}
+
+type RetryBudgetStats struct {
+	// Tokens is number of retries currently available.
+	Tokens float64
+	// Requests is number of runs deposited to the budget.
+	Requests uint64
+	// Retries is number of retries withdrawn from the budget.
+	Retries uint64
+	// Exhausted is number of retries rejected by the empty budget.
+	Exhausted uint64
+}
+
+func (b *RetryBudget) Stats() RetryBudgetStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return RetryBudgetStats{
+		Tokens:    b.tokens,
+		Requests:  b.requests,
+		Retries:   b.retries,
+		Exhausted: b.exhausted,
+	}
+}
+
+func (b *RetryBudget) deposit() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.requests++
+	b.tokens = min(b.tokens+b.ratio, b.maxTokens)
+}
+
+func (b *RetryBudget) withdraw() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.tokens < 1 {
+		b.exhausted++
+
+		return false
+	}
+
+	b.tokens--
+	b.retries++
+
+	return true
+}
+
+// WithRetryBudget draws every retry of Run from budget,
+// overrides budget attached to beginner by BeginnerWithRetryBudget.
+func WithRetryBudget(budget *RetryBudget) Option {
+	return func(o *options) {
+		o.retryBudget = budget
+	}
+}
+
+type retryBudgetBeginner struct {
+	Beginner
+	budget *RetryBudget
+}
+
+// BeginnerWithRetryBudget attaches budget to beginner, Run draws retries of all its transactions from budget.
+func BeginnerWithRetryBudget(beginner Beginner, budget *RetryBudget) Beginner {
+	return retryBudgetBeginner{
+		Beginner: beginner,
+		budget:   budget,
+	}
+}
+
+func (r retryBudgetBeginner) RetryBudget() *RetryBudget {
+	return r.budget
+}
+
+func (r retryBudgetBeginner) Driver() Driver {
+	driver, _ := getDriver(r.Beginner)
+
+	return driver
+}
+
+func (r retryBudgetBeginner) TxEnabled(ctx context.Context) bool {
+	return txEnabled(ctx, r.Beginner)
+}
+
+func (r retryBudgetBeginner) tracker() (runTracker, bool) {
+	return getRunTracker(r.Beginner)
+}
+
+// getRetryBudget returns budget attached by BeginnerWithRetryBudget, wrapping beginners forward budget of the wrapped one.
+func getRetryBudget(x any) *RetryBudget {
+	budget, ok := x.(interface{ RetryBudget() *RetryBudget })
+	if !ok {
+		return nil
+	}
+
+	return budget.RetryBudget()
+}
+
+func retryBudgetExec(exec func() error, budget *RetryBudget) func() error {
+	if budget == nil {
+		return exec
+	}
+
+	return func() error {
+		budget.deposit()
+
+		return exec()
+	}
+}
@@ -0,0 +1,115 @@
+package tx_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/amidgo/tx"
+	txmocks "github.com/amidgo/tx/mocks"
+)
+
+func Test_RetryBudget(t *testing.T) {
+	serializationDriver := txmocks.ExpectDriverError(errors.Is, io.ErrUnexpectedEOF, errors.Join(tx.ErrSerialization, io.ErrUnexpectedEOF))
+	failedTx := txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollback(nil), nil)
+	serializationFailure := func(*testing.T, context.Context) error { return io.ErrUnexpectedEOF }
+
+	budget := tx.NewRetryBudget(0.5, 1)
+
+	t.Run("retry withdrawn from full budget", (&runDriverTest{
+		DriverMock:   txmocks.JoinDrivers(serializationDriver, serializationDriver),
+		BeginnerMock: txmocks.JoinBeginners(failedTx, failedTx),
+		WithTx:       serializationFailure,
+		Opts: []tx.Option{
+			tx.RetrySerialization(1),
+			tx.WithRetryBudget(budget),
+		},
+		ExpectedErrors:   []error{tx.ErrSerializationRepeatTimesExcedeed},
+		UnexpectedErrors: []error{tx.ErrRetryBudgetExhausted},
+	}).Test)
+
+	stats := budget.Stats()
+	if stats != (tx.RetryBudgetStats{Tokens: 0, Requests: 1, Retries: 1}) {
+		t.Fatalf("unexpected stats, %+v", stats)
+	}
+
+	t.Run("budget exhausted, fail fast", (&runDriverTest{
+		DriverMock:   serializationDriver,
+		BeginnerMock: failedTx,
+		WithTx:       serializationFailure,
+		Opts: []tx.Option{
+			tx.RetrySerialization(3),
+			tx.WithRetryBudget(budget),
+		},
+		ExpectedErrors:   []error{tx.ErrRetryBudgetExhausted, tx.ErrSerialization},
+		UnexpectedErrors: []error{tx.ErrSerializationRepeatTimesExcedeed},
+	}).Test)
+
+	stats = budget.Stats()
+	if stats != (tx.RetryBudgetStats{Tokens: 0.5, Requests: 2, Retries: 1, Exhausted: 1}) {
+		t.Fatalf("unexpected stats, %+v", stats)
+	}
+
+	t.Run("budget attached to beginner", func(t *testing.T) {
+		beginner := tx.BeginnerWithRetryBudget(
+			tx.BeginnerWithDriver(
+				txmocks.JoinBeginners(failedTx, txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil))(t),
+				serializationDriver(t),
+			),
+			budget,
+		)
+
+		calls := 0
+
+		err := tx.Run(context.Background(), beginner,
+			func(context.Context) error {
+				calls++
+				if calls == 1 {
+					return io.ErrUnexpectedEOF
+				}
+
+				return nil
+			},
+			nil,
+			tx.RetrySerialization(1),
+		)
+		if err != nil {
+			t.Fatalf("expected no error, actual %+v", err)
+		}
+	})
+
+	stats = budget.Stats()
+	if stats != (tx.RetryBudgetStats{Tokens: 0, Requests: 3, Retries: 2, Exhausted: 1}) {
+		t.Fatalf("unexpected stats, %+v", stats)
+	}
+}
+
+func Test_RetryBudget_WrappedBeginner(t *testing.T) {
+	budget := tx.NewRetryBudget(0, 0)
+
+	beginner := tx.NewBreakerBeginner(
+		tx.BeginnerWithDriver(
+			tx.BeginnerWithRetryBudget(
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollback(nil), nil)(t),
+				budget,
+			),
+			txmocks.ExpectDriverError(errors.Is, io.ErrUnexpectedEOF, errors.Join(tx.ErrSerialization, io.ErrUnexpectedEOF))(t),
+		),
+		10,
+	)
+
+	err := tx.Run(context.Background(), beginner,
+		func(context.Context) error { return io.ErrUnexpectedEOF },
+		nil,
+		tx.RetrySerialization(1),
+	)
+	if !errors.Is(err, tx.ErrRetryBudgetExhausted) {
+		t.Fatalf("expected budget of wrapped beginner to be exhausted, actual %+v", err)
+	}
+
+	stats := budget.Stats()
+	if stats != (tx.RetryBudgetStats{Requests: 1, Exhausted: 1}) {
+		t.Fatalf("unexpected stats, %+v", stats)
+	}
+}
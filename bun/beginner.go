@@ -3,21 +3,23 @@ package buntx
 import (
 	"context"
 	"database/sql"
-	"sync"
 
 	ttn "github.com/amidgo/tx"
+	"github.com/amidgo/tx/internal/leak"
+	"github.com/amidgo/tx/internal/nested"
+	"github.com/amidgo/tx/internal/sqlctx"
+	"github.com/amidgo/tx/internal/txstate"
 	"github.com/uptrace/bun"
 )
 
-type txKey struct{}
-
 var _ ttn.Tx = (*tx)(nil)
 
 type tx struct {
 	bunTx bun.Tx
 
-	ctx  context.Context
-	once sync.Once
+	ctx     context.Context
+	state   txstate.Machine
+	untrack func()
 }
 
 func (s *tx) Context() context.Context {
@@ -25,61 +27,95 @@ func (s *tx) Context() context.Context {
 }
 
 func (s *tx) Commit() error {
-	s.clearTx()
+	defer s.untrack()
 
-	return s.bunTx.Commit()
+	return s.state.Commit(s.bunTx.Commit)
 }
 
 func (s *tx) Rollback() error {
-	s.clearTx()
+	defer s.untrack()
 
-	return s.bunTx.Rollback()
+	return s.state.Rollback(s.bunTx.Rollback)
 }
 
-func (s *tx) clearTx() {
-	s.once.Do(func() {
-		s.ctx = context.WithValue(s.ctx, txKey{}, nil)
-	})
+func (s *tx) State() ttn.TxState {
+	return ttn.TxState(s.state.State())
 }
 
 var _ ttn.Beginner = (*Beginner)(nil)
 
 type Beginner struct {
-	db *bun.DB
+	db      *bun.DB
+	opts    ttn.BeginnerOptions
+	tracker *leak.Tracker
 }
 
-func NewBeginner(db *bun.DB) *Beginner {
+func NewBeginner(db *bun.DB, opts ...ttn.BeginnerOption) *Beginner {
+	options := ttn.ApplyBeginnerOptions(opts...)
+
 	return &Beginner{
-		db: db,
+		db:      db,
+		opts:    options,
+		tracker: leak.New(options),
 	}
 }
 
 func (s *Beginner) Begin(ctx context.Context) (ttn.Tx, error) {
+	err := nested.CheckBegin(ctx, s.db.DB, s.opts)
+	if err != nil {
+		return nil, err
+	}
+
 	bunTx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	return &tx{
-		bunTx: bunTx,
-		ctx:   s.txContext(ctx, bunTx),
-	}, nil
+	return s.newTx(ctx, bunTx), nil
 }
 
 func (s *Beginner) BeginTx(ctx context.Context, opts *sql.TxOptions) (ttn.Tx, error) {
+	err := nested.CheckBegin(ctx, s.db.DB, s.opts)
+	if err != nil {
+		return nil, err
+	}
+
 	bunTx, err := s.db.BeginTx(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	return &tx{
-		bunTx: bunTx,
-		ctx:   s.txContext(ctx, bunTx),
-	}, nil
+	return s.newTx(ctx, bunTx), nil
+}
+
+func (s *Beginner) newTx(ctx context.Context, bunTx bun.Tx) *tx {
+	stack := nested.Stack(s.opts)
+
+	tx := &tx{bunTx: bunTx, untrack: s.tracker.Track(stack)}
+	tx.ctx = sqlctx.WithTx(ctx,
+		&sqlctx.Tx{
+			DB:     s.db.DB,
+			Tx:     bunTx.Tx,
+			Native: bunTx,
+			State:  &tx.state,
+			Stack:  stack,
+		},
+	)
+
+	return tx
 }
 
-func (s *Beginner) txContext(ctx context.Context, bunTx bun.Tx) context.Context {
-	return context.WithValue(ctx, txKey{}, bunTx)
+// ContextWithTx places externally created bunTx into ctx,
+// so Beginner.Executor and Beginner.TxEnabled pick it up.
+// Caller remains responsible for commit or rollback of bunTx.
+func ContextWithTx(ctx context.Context, bunTx bun.Tx) context.Context {
+	return sqlctx.WithTx(ctx,
+		&sqlctx.Tx{
+			Tx:     bunTx.Tx,
+			Native: bunTx,
+			State:  &txstate.Machine{},
+		},
+	)
 }
 
 func (s *Beginner) Executor(ctx context.Context) Executor {
@@ -89,18 +125,33 @@ func (s *Beginner) Executor(ctx context.Context) Executor {
 }
 
 func (s *Beginner) TxEnabled(ctx context.Context) bool {
-	_, ok := s.executor(ctx)
+	_, ok := sqlctx.FromContext(ctx, s.db.DB)
 
 	return ok
 }
 
+// OpenTransactions returns transactions that are neither committed nor rolled back,
+// transactions are tracked only if the beginner was created with ttn.TrackTransactions.
+func (s *Beginner) OpenTransactions() []ttn.OpenTransaction {
+	return s.tracker.Open()
+}
+
+// executor returns transaction began on the same *sql.DB by any of the adapters,
+// transaction began by another adapter is wrapped into view.
 func (s *Beginner) executor(ctx context.Context) (Executor, bool) {
-	tx, ok := ctx.Value(txKey{}).(bun.Tx)
+	tx, ok := sqlctx.FromContext(ctx, s.db.DB)
 	if !ok {
+		nested.CheckExecutor(ctx, s.db.DB, s.opts)
+
 		return s.db, false
 	}
 
-	return tx, true
+	bunTx, ok := tx.Native.(bun.Tx)
+	if ok {
+		return bunTx, true
+	}
+
+	return newTxView(s.db, tx.Tx), true
 }
 
 func (s *Beginner) WithTx(
@@ -114,7 +165,7 @@ func (s *Beginner) WithTx(
 			exec := s.Executor(txContext)
 
 			// must be tx without executor
-			return f(ctx, exec)
+			return f(sqlctx.WithHeld(ctx, txContext, s.db.DB), exec)
 		},
 		txOpts,
 		opts...,
@@ -228,3 +228,69 @@ func Test_BunBeginner_Error(t *testing.T) {
 
 	txtest.AssertBeginError(t, ctx, beginner, nil, context.Canceled)
 }
+
+func Test_BunBeginner_State(t *testing.T) {
+	t.Parallel()
+
+	db := postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil)
+
+	bunDB := bun.NewDB(db, pgdialect.New())
+
+	beginner := buntx.NewBeginner(bunDB)
+
+	tx, err := beginner.Begin(context.Background())
+	require.NoError(t, err)
+
+	txtest.AssertTxDone(t, beginner, tx)
+}
+
+func Test_BunBeginner_ContextWithTx(t *testing.T) {
+	t.Parallel()
+
+	const createUsersTableQuery = `
+		CREATE TABLE users (
+			id uuid primary key,
+			age smallint not null
+		)
+	`
+
+	db := postgrescontainer.ReuseForTesting(t,
+		reusable.Postgres(),
+		migrations.Nil,
+		createUsersTableQuery,
+	)
+
+	bunDB := bun.NewDB(db, pgdialect.New())
+
+	beginner := buntx.NewBeginner(bunDB)
+
+	bunTx, err := bunDB.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+
+	ctx := buntx.ContextWithTx(context.Background(), bunTx)
+
+	require.True(t, beginner.TxEnabled(ctx))
+	require.Equal(t, bunTx, beginner.Executor(ctx))
+
+	userID := uuid.New()
+	userAge := 100
+
+	err = tx.Run(ctx, beginner,
+		func(txContext context.Context) error {
+			_, err := beginner.Executor(txContext).ExecContext(txContext, "INSERT INTO users (id, age) VALUES (?, ?)", userID, userAge)
+
+			return err
+		},
+		nil,
+		tx.WithPropagation(tx.PropagationRequired),
+	)
+	require.NoError(t, err)
+
+	txtest.AssertUserNotFound(t, bunDB, userID, txtest.WithQuestionMarkPlaceholder)
+	txtest.AssertUserExists(t, bunTx, userID, userAge, txtest.WithQuestionMarkPlaceholder)
+
+	err = bunTx.Rollback()
+	require.NoError(t, err)
+
+	txtest.AssertUserNotFound(t, bunDB, userID, txtest.WithQuestionMarkPlaceholder)
+}
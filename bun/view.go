@@ -0,0 +1,112 @@
+package buntx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+)
+
+var ErrNestedViewTx = errors.New("buntx: nested transaction is not supported on transaction began by another adapter")
+
+// txView is a bun view over *sql.Tx began by another adapter on the same *sql.DB.
+// bun.Tx can't be created from *sql.Tx, so queries are built by db and run on sqlTx.
+type txView struct {
+	db    *bun.DB
+	sqlTx *sql.Tx
+}
+
+var _ Executor = txView{}
+
+func newTxView(db *bun.DB, sqlTx *sql.Tx) txView {
+	return txView{
+		db:    db,
+		sqlTx: sqlTx,
+	}
+}
+
+func (v txView) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return v.sqlTx.ExecContext(ctx, v.format(query, args))
+}
+
+func (v txView) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return v.sqlTx.QueryContext(ctx, v.format(query, args))
+}
+
+func (v txView) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return v.sqlTx.QueryRowContext(ctx, v.format(query, args))
+}
+
+func (v txView) format(query string, args []interface{}) string {
+	return v.db.Formatter().FormatQuery(query, args...)
+}
+
+func (v txView) Dialect() schema.Dialect {
+	return v.db.Dialect()
+}
+
+func (v txView) NewValues(model interface{}) *bun.ValuesQuery {
+	return bun.NewValuesQuery(v.db, model).Conn(v.sqlTx)
+}
+
+func (v txView) NewSelect() *bun.SelectQuery {
+	return bun.NewSelectQuery(v.db).Conn(v.sqlTx)
+}
+
+func (v txView) NewInsert() *bun.InsertQuery {
+	return bun.NewInsertQuery(v.db).Conn(v.sqlTx)
+}
+
+func (v txView) NewUpdate() *bun.UpdateQuery {
+	return bun.NewUpdateQuery(v.db).Conn(v.sqlTx)
+}
+
+func (v txView) NewDelete() *bun.DeleteQuery {
+	return bun.NewDeleteQuery(v.db).Conn(v.sqlTx)
+}
+
+func (v txView) NewMerge() *bun.MergeQuery {
+	return bun.NewMergeQuery(v.db).Conn(v.sqlTx)
+}
+
+func (v txView) NewRaw(query string, args ...interface{}) *bun.RawQuery {
+	return bun.NewRawQuery(v.db, query, args...).Conn(v.sqlTx)
+}
+
+func (v txView) NewCreateTable() *bun.CreateTableQuery {
+	return bun.NewCreateTableQuery(v.db).Conn(v.sqlTx)
+}
+
+func (v txView) NewDropTable() *bun.DropTableQuery {
+	return bun.NewDropTableQuery(v.db).Conn(v.sqlTx)
+}
+
+func (v txView) NewCreateIndex() *bun.CreateIndexQuery {
+	return bun.NewCreateIndexQuery(v.db).Conn(v.sqlTx)
+}
+
+func (v txView) NewDropIndex() *bun.DropIndexQuery {
+	return bun.NewDropIndexQuery(v.db).Conn(v.sqlTx)
+}
+
+func (v txView) NewTruncateTable() *bun.TruncateTableQuery {
+	return bun.NewTruncateTableQuery(v.db).Conn(v.sqlTx)
+}
+
+func (v txView) NewAddColumn() *bun.AddColumnQuery {
+	return bun.NewAddColumnQuery(v.db).Conn(v.sqlTx)
+}
+
+func (v txView) NewDropColumn() *bun.DropColumnQuery {
+	return bun.NewDropColumnQuery(v.db).Conn(v.sqlTx)
+}
+
+func (v txView) BeginTx(context.Context, *sql.TxOptions) (bun.Tx, error) {
+	return bun.Tx{}, ErrNestedViewTx
+}
+
+func (v txView) RunInTx(context.Context, *sql.TxOptions, func(ctx context.Context, tx bun.Tx) error) error {
+	return ErrNestedViewTx
+}
@@ -0,0 +1,103 @@
+package buntx_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	postgrescontainer "github.com/amidgo/containers/postgres"
+	"github.com/amidgo/containers/postgres/migrations"
+	"github.com/amidgo/tx"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+
+	"github.com/amidgo/tx/internal/reusable"
+	txtest "github.com/amidgo/tx/internal/testing"
+
+	buntx "github.com/amidgo/tx/bun"
+	sqltx "github.com/amidgo/tx/sql"
+)
+
+type user struct {
+	bun.BaseModel `bun:"table:users"`
+
+	ID  uuid.UUID `bun:"id,pk"`
+	Age int       `bun:"age"`
+}
+
+func Test_BunBeginner_SharedTx(t *testing.T) {
+	t.Parallel()
+
+	const createUsersTableQuery = `
+		CREATE TABLE users (
+			id uuid primary key,
+			age smallint not null
+		)
+	`
+
+	db := postgrescontainer.ReuseForTesting(t,
+		reusable.Postgres(),
+		migrations.Nil,
+		createUsersTableQuery,
+	)
+
+	sqlBeginner := sqltx.NewBeginner(db)
+	bunBeginner := buntx.NewBeginner(bun.NewDB(db, pgdialect.New()))
+
+	t.Run("tx began by sqltx, bun view expected", func(t *testing.T) {
+		bunUser := &user{ID: uuid.New(), Age: 100}
+
+		err := tx.Run(context.Background(), sqlBeginner,
+			func(txContext context.Context) error {
+				require.True(t, bunBeginner.TxEnabled(txContext))
+
+				exec := bunBeginner.Executor(txContext)
+
+				_, err := exec.NewInsert().Model(bunUser).Exec(txContext)
+				require.NoError(t, err)
+
+				txtest.AssertUserExists(t, sqlBeginner.Executor(txContext), bunUser.ID, bunUser.Age)
+				txtest.AssertUserNotFound(t, db, bunUser.ID)
+
+				_, err = exec.BeginTx(txContext, nil)
+				require.ErrorIs(t, err, buntx.ErrNestedViewTx)
+
+				return nil
+			},
+			nil,
+		)
+		require.NoError(t, err)
+
+		txtest.AssertUserExists(t, db, bunUser.ID, bunUser.Age)
+	})
+
+	t.Run("tx began by buntx, sql executor expected", func(t *testing.T) {
+		userID := uuid.New()
+		userAge := 100
+
+		err := tx.Run(context.Background(), bunBeginner,
+			func(txContext context.Context) error {
+				require.True(t, sqlBeginner.TxEnabled(txContext))
+
+				_, err := sqlBeginner.Executor(txContext).ExecContext(txContext,
+					"INSERT INTO users (id, age) VALUES ($1, $2)",
+					userID, userAge,
+				)
+				require.NoError(t, err)
+
+				txtest.AssertUserExists(t, bunBeginner.Executor(txContext), userID, userAge, txtest.WithQuestionMarkPlaceholder)
+				txtest.AssertUserNotFound(t, db, userID)
+
+				return errStubRollback
+			},
+			nil,
+		)
+		require.ErrorIs(t, err, errStubRollback)
+
+		txtest.AssertUserNotFound(t, db, userID)
+	})
+}
+
+var errStubRollback = errors.New("stub rollback")
@@ -0,0 +1,81 @@
+package tx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrCommitUnknown is a commit failure after which the transaction may or may not have been committed,
+// e.g. connection was lost while waiting for commit result.
+var ErrCommitUnknown = errors.New("commit outcome unknown")
+
+// CommitUnknownError is returned by CommitDriver when commit outcome is unknown.
+type CommitUnknownError struct {
+	Err error
+}
+
+func (e *CommitUnknownError) Error() string {
+	return fmt.Sprintf("%s, %s", ErrCommitUnknown, e.Err)
+}
+
+func (e *CommitUnknownError) Unwrap() []error {
+	return []error{ErrCommitUnknown, e.Err}
+}
+
+// CommitDriver is optionally implemented by Driver to classify commit errors,
+// commit errors with unknown outcome must be reported by *CommitUnknownError.
+// Drivers without CommitError classify commit errors by Error.
+type CommitDriver interface {
+	Driver
+	CommitError(err error) error
+}
+
+// VerifyCommit sets verify called by Run when CommitDriver reports unknown commit outcome,
+// verify checks in ctx whether the transaction was committed, e.g. looks up a row written in withTx.
+// Run succeeds if the transaction was committed and returns definite ErrCommit otherwise.
+// If verify fails the outcome stays unknown and Run returns ErrCommitUnknown joined with verify error.
+func VerifyCommit(verify func(ctx context.Context) (committed bool, err error)) Option {
+	return func(o *options) {
+		o.verifyCommit = verify
+	}
+}
+
+func commitDriverError(driver Driver, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	commitDriver, ok := driver.(CommitDriver)
+	if !ok {
+		return driverError(driver, err)
+	}
+
+	return commitDriver.CommitError(err)
+}
+
+func verifyCommitExec(
+	ctx context.Context,
+	exec func() error,
+	verify func(ctx context.Context) (bool, error),
+) func() error {
+	return func() error {
+		err := exec()
+
+		var unknownErr *CommitUnknownError
+		if !errors.As(err, &unknownErr) {
+			return err
+		}
+
+		committed, verifyErr := verify(ctx)
+
+		switch {
+		case verifyErr != nil:
+			return errors.Join(err, fmt.Errorf("verify commit, %w", verifyErr))
+		case committed:
+			return nil
+		default:
+			return errors.Join(ErrCommit, unknownErr.Err)
+		}
+	}
+}
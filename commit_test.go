@@ -0,0 +1,100 @@
+package tx_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/amidgo/tx"
+	txmocks "github.com/amidgo/tx/mocks"
+)
+
+type unknownCommitDriver struct{}
+
+func (unknownCommitDriver) Error(err error) error {
+	return err
+}
+
+func (unknownCommitDriver) CommitError(err error) error {
+	return &tx.CommitUnknownError{Err: err}
+}
+
+func Test_Run_VerifyCommit(t *testing.T) {
+	errVerify := errors.New("verify failed")
+
+	tests := []struct {
+		Name             string
+		Driver           tx.Driver
+		Verify           func(ctx context.Context) (bool, error)
+		ExpectedErrors   []error
+		UnexpectedErrors []error
+	}{
+		{
+			Name:           "unknown commit, no verify",
+			Driver:         unknownCommitDriver{},
+			ExpectedErrors: []error{tx.ErrCommit, tx.ErrCommitUnknown, io.ErrUnexpectedEOF},
+		},
+		{
+			Name:   "unknown commit, verified committed",
+			Driver: unknownCommitDriver{},
+			Verify: func(context.Context) (bool, error) { return true, nil },
+		},
+		{
+			Name:             "unknown commit, verified not committed",
+			Driver:           unknownCommitDriver{},
+			Verify:           func(context.Context) (bool, error) { return false, nil },
+			ExpectedErrors:   []error{tx.ErrCommit, io.ErrUnexpectedEOF},
+			UnexpectedErrors: []error{tx.ErrCommitUnknown},
+		},
+		{
+			Name:           "unknown commit, verify failed",
+			Driver:         unknownCommitDriver{},
+			Verify:         func(context.Context) (bool, error) { return false, errVerify },
+			ExpectedErrors: []error{tx.ErrCommit, tx.ErrCommitUnknown, errVerify},
+		},
+		{
+			Name:   "driver without commit classification",
+			Driver: txmocks.ExpectDriverError(errors.Is, io.ErrUnexpectedEOF, io.ErrUnexpectedEOF)(t),
+			Verify: func(context.Context) (bool, error) {
+				t.Fatal("unexpected verify call")
+
+				return false, nil
+			},
+			ExpectedErrors:   []error{tx.ErrCommit, io.ErrUnexpectedEOF},
+			UnexpectedErrors: []error{tx.ErrCommitUnknown},
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			beginner := tx.BeginnerWithDriver(
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollbackAfterFailedCommit(io.ErrUnexpectedEOF), nil)(t),
+				tst.Driver,
+			)
+
+			opts := []tx.Option{}
+			if tst.Verify != nil {
+				opts = append(opts, tx.VerifyCommit(tst.Verify))
+			}
+
+			err := tx.Run(context.Background(), beginner, func(context.Context) error { return nil }, nil, opts...)
+
+			if len(tst.ExpectedErrors) == 0 && err != nil {
+				t.Fatalf("expected no error, actual %+v", err)
+			}
+
+			for _, expectedErr := range tst.ExpectedErrors {
+				if !errors.Is(err, expectedErr) {
+					t.Fatalf("unexpected error, expect %+v, actual %+v", expectedErr, err)
+				}
+			}
+
+			for _, unexpectedErr := range tst.UnexpectedErrors {
+				if errors.Is(err, unexpectedErr) {
+					t.Fatalf("unexpected error, unexpect %+v, actual %+v", unexpectedErr, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package tx
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/amidgo/tx/internal/sqlctx"
+)
+
+var (
+	ErrIdempotencyConflict    = errors.New("idempotency key reused with different request")
+	ErrIdempotencyUnsupported = errors.New("idempotency requires transaction of database/sql based beginner")
+)
+
+// CreateIdempotencyTableQuery creates table of RunIdempotent responses in Postgres database.
+const CreateIdempotencyTableQuery = `
+CREATE TABLE IF NOT EXISTS tx_idempotency (
+	key text PRIMARY KEY,
+	fingerprint text NOT NULL,
+	response bytea,
+	created_at timestamptz NOT NULL DEFAULT now()
+)`
+
+type IdempotencyKey struct {
+	Key string
+	// Fingerprint identifies request content, e.g. hash of the request body.
+	Fingerprint string
+}
+
+// RunIdempotent runs run with Run once per key.Key and returns its response,
+// replay with the same key returns stored response without running run,
+// replay with another fingerprint returns ErrIdempotencyConflict.
+//
+// Key, fingerprint and JSON of response are stored in the transaction of run,
+// so failed run stores nothing and may be replayed.
+// Concurrent duplicate blocks on the key until the first transaction ends.
+// Beginner must be an adapter beginner, optionally wrapped.
+// ErrAmbiguousTx is returned if ctx already holds transaction of another database.
+func RunIdempotent[Response any](
+	ctx context.Context,
+	beginner Beginner,
+	key IdempotencyKey,
+	run func(txContext context.Context) (Response, error),
+	txOpts *sql.TxOptions,
+	opts ...Option,
+) (Response, error) {
+	var zero, response Response
+
+	err := Run(ctx, beginner,
+		func(txContext context.Context) error {
+			response = zero
+
+			tx, err := sqlctx.Current(txContext)
+			if errors.Is(err, sqlctx.ErrNoTx) {
+				return ErrIdempotencyUnsupported
+			}
+
+			if err != nil {
+				return err
+			}
+
+			// waits for the concurrent transaction inserted the same key
+			result, err := tx.Tx.ExecContext(txContext,
+				"INSERT INTO tx_idempotency (key, fingerprint) VALUES ($1, $2) ON CONFLICT (key) DO NOTHING",
+				key.Key,
+				key.Fingerprint,
+			)
+			if err != nil {
+				return err
+			}
+
+			inserted, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+
+			if inserted == 0 {
+				return storedResponse(txContext, tx.Tx, key, &response)
+			}
+
+			response, err = run(txContext)
+			if err != nil {
+				return err
+			}
+
+			data, err := json.Marshal(response)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.Tx.ExecContext(txContext,
+				"UPDATE tx_idempotency SET response = $1 WHERE key = $2",
+				data,
+				key.Key,
+			)
+
+			return err
+		},
+		txOpts,
+		opts...,
+	)
+	if err != nil {
+		return zero, err
+	}
+
+	return response, nil
+}
+
+func storedResponse(ctx context.Context, tx *sql.Tx, key IdempotencyKey, response any) error {
+	var (
+		fingerprint string
+		data        []byte
+	)
+
+	err := tx.QueryRowContext(ctx,
+		"SELECT fingerprint, response FROM tx_idempotency WHERE key = $1",
+		key.Key,
+	).Scan(&fingerprint, &data)
+	if err != nil {
+		return err
+	}
+
+	if fingerprint != key.Fingerprint {
+		return ErrIdempotencyConflict
+	}
+
+	return json.Unmarshal(data, response)
+}
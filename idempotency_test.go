@@ -0,0 +1,30 @@
+package tx_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/amidgo/tx"
+	txmocks "github.com/amidgo/tx/mocks"
+)
+
+func Test_RunIdempotent_Unsupported(t *testing.T) {
+	response, err := tx.RunIdempotent(context.Background(),
+		txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollback(nil), nil)(t),
+		tx.IdempotencyKey{Key: "key", Fingerprint: "fingerprint"},
+		func(context.Context) (string, error) {
+			t.Fatal("run must not be called")
+
+			return "response", nil
+		},
+		nil,
+	)
+	if !errors.Is(err, tx.ErrIdempotencyUnsupported) {
+		t.Fatalf("unexpected error, expected %s, actual %v", tx.ErrIdempotencyUnsupported, err)
+	}
+
+	if response != "" {
+		t.Fatalf("zero response expected, actual %s", response)
+	}
+}
@@ -0,0 +1,74 @@
+// Package inbox implements idempotent consumer over the transactions of sql, sqlx and bun adapters.
+package inbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/amidgo/tx"
+	"github.com/amidgo/tx/internal/sqlctx"
+)
+
+var (
+	ErrAlreadyProcessed = errors.New("inbox: message already processed")
+	ErrNoTx             = errors.New("inbox: transaction not found in context")
+
+	errMarkProcessed = errors.New("inbox: mark message processed")
+)
+
+// CreateTableQuery creates inbox table in Postgres database.
+const CreateTableQuery = `
+CREATE TABLE IF NOT EXISTS tx_inbox (
+	message_id text PRIMARY KEY,
+	processed_at timestamptz NOT NULL DEFAULT now()
+)`
+
+// Process runs handle with tx.Run only once per messageID.
+//
+// Message id is inserted into inbox table in the same transaction before handle,
+// unique violation of the insert classified by Driver of beginner skips handle and reports ErrAlreadyProcessed.
+// Beginner must be an adapter beginner, optionally wrapped with tx.BeginnerWithDriver.
+// tx.ErrAmbiguousTx is returned if ctx already holds transaction of another database.
+func Process(
+	ctx context.Context,
+	beginner tx.Beginner,
+	messageID string,
+	handle func(txContext context.Context) error,
+	txOpts *sql.TxOptions,
+	opts ...tx.Option,
+) error {
+	err := tx.Run(ctx, beginner,
+		func(txContext context.Context) error {
+			err := markProcessed(txContext, messageID)
+			if err != nil {
+				return errors.Join(errMarkProcessed, err)
+			}
+
+			return handle(txContext)
+		},
+		txOpts,
+		opts...,
+	)
+
+	if errors.Is(err, errMarkProcessed) && errors.Is(err, tx.ErrUniqueViolation) {
+		return ErrAlreadyProcessed
+	}
+
+	return err
+}
+
+func markProcessed(txContext context.Context, messageID string) error {
+	tx, err := sqlctx.Current(txContext)
+	if errors.Is(err, sqlctx.ErrNoTx) {
+		return ErrNoTx
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Tx.ExecContext(txContext, "INSERT INTO tx_inbox (message_id) VALUES ($1)", messageID)
+
+	return err
+}
@@ -0,0 +1,103 @@
+package inbox_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	postgrescontainer "github.com/amidgo/containers/postgres"
+	"github.com/amidgo/containers/postgres/migrations"
+	"github.com/amidgo/tx"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amidgo/tx/inbox"
+	"github.com/amidgo/tx/internal/reusable"
+	txtest "github.com/amidgo/tx/internal/testing"
+	txmocks "github.com/amidgo/tx/mocks"
+	pgxtx "github.com/amidgo/tx/pgx"
+	sqltx "github.com/amidgo/tx/sql"
+)
+
+func Test_Process_NoTx(t *testing.T) {
+	t.Parallel()
+
+	err := inbox.Process(context.Background(),
+		txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollback(nil), nil)(t),
+		"message",
+		func(context.Context) error {
+			t.Fatal("handle must not be called")
+
+			return nil
+		},
+		nil,
+	)
+	require.ErrorIs(t, err, inbox.ErrNoTx)
+}
+
+func Test_Process(t *testing.T) {
+	t.Parallel()
+
+	const createUsersTableQuery = `
+		CREATE TABLE users (
+			id uuid primary key,
+			age smallint not null
+		)
+	`
+
+	db := postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil,
+		createUsersTableQuery,
+		inbox.CreateTableQuery,
+	)
+
+	sqlBeginner := sqltx.NewBeginner(db)
+	beginner := tx.BeginnerWithDriver(sqlBeginner, pgxtx.Driver())
+
+	ctx := context.Background()
+
+	userID := uuid.New()
+	handled := 0
+
+	insertUser := func(txContext context.Context) error {
+		handled++
+
+		_, err := sqlBeginner.Executor(txContext).ExecContext(txContext,
+			"INSERT INTO users (id, age) VALUES ($1, $2)",
+			userID, handled,
+		)
+
+		return err
+	}
+
+	errHandle := errors.New("handle failed")
+
+	err := inbox.Process(ctx, beginner, "first",
+		func(txContext context.Context) error {
+			err := insertUser(txContext)
+			require.NoError(t, err)
+
+			return errHandle
+		},
+		nil,
+	)
+	require.ErrorIs(t, err, errHandle)
+
+	txtest.AssertUserNotFound(t, db, userID)
+
+	err = inbox.Process(ctx, beginner, "first", insertUser, nil)
+	require.NoError(t, err)
+
+	txtest.AssertUserExists(t, db, userID, 2)
+
+	err = inbox.Process(ctx, beginner, "first", insertUser, nil)
+	require.ErrorIs(t, err, inbox.ErrAlreadyProcessed)
+	require.Equal(t, 2, handled)
+
+	// unique violation of handle is not a duplicate message
+	err = inbox.Process(ctx, beginner, "second", insertUser, nil)
+	require.ErrorIs(t, err, tx.ErrUniqueViolation)
+	require.NotErrorIs(t, err, inbox.ErrAlreadyProcessed)
+
+	err = inbox.Process(ctx, beginner, "second", func(context.Context) error { return nil }, nil)
+	require.NoError(t, err)
+}
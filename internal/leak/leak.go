@@ -0,0 +1,93 @@
+// Package leak tracks open transactions of adapter beginners.
+package leak
+
+import (
+	"runtime/debug"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/amidgo/tx"
+)
+
+type entry struct {
+	stack   []byte
+	beganAt time.Time
+}
+
+// Tracker records open transactions, nil Tracker tracks nothing.
+type Tracker struct {
+	mu   sync.Mutex
+	open map[*entry]struct{}
+}
+
+// New returns nil Tracker if tracking is disabled by opts.
+func New(opts tx.BeginnerOptions) *Tracker {
+	if !opts.TrackTransactions {
+		return nil
+	}
+
+	return &Tracker{
+		open: make(map[*entry]struct{}),
+	}
+}
+
+// Track records transaction began by the caller with stack, if stack is nil stack of the caller is used.
+// Returned untrack must be called when the transaction ends.
+func (t *Tracker) Track(stack []byte) (untrack func()) {
+	if t == nil {
+		return func() {}
+	}
+
+	if stack == nil {
+		stack = debug.Stack()
+	}
+
+	e := &entry{
+		stack:   stack,
+		beganAt: time.Now(),
+	}
+
+	t.mu.Lock()
+	t.open[e] = struct{}{}
+	t.mu.Unlock()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			t.mu.Lock()
+			delete(t.open, e)
+			t.mu.Unlock()
+		})
+	}
+}
+
+// Open returns open transactions from the oldest.
+func (t *Tracker) Open() []tx.OpenTransaction {
+	if t == nil {
+		return nil
+	}
+
+	now := time.Now()
+
+	t.mu.Lock()
+
+	open := make([]tx.OpenTransaction, 0, len(t.open))
+
+	for e := range t.open {
+		open = append(open, tx.OpenTransaction{
+			Stack:   e.stack,
+			BeganAt: e.beganAt,
+			Age:     now.Sub(e.beganAt),
+		})
+	}
+
+	t.mu.Unlock()
+
+	slices.SortFunc(open, func(a, b tx.OpenTransaction) int {
+		return a.BeganAt.Compare(b.BeganAt)
+	})
+
+	return open
+}
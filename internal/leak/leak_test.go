@@ -0,0 +1,60 @@
+package leak_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/amidgo/tx"
+	"github.com/amidgo/tx/internal/leak"
+)
+
+func Test_Tracker(t *testing.T) {
+	t.Parallel()
+
+	tracker := leak.New(tx.ApplyBeginnerOptions(tx.TrackTransactions()))
+
+	untrackFirst := tracker.Track([]byte("first"))
+	untrackSecond := tracker.Track(nil)
+
+	open := tracker.Open()
+	if len(open) != 2 {
+		t.Fatalf("expected 2 open transactions, actual %d", len(open))
+	}
+
+	if !bytes.Equal(open[0].Stack, []byte("first")) {
+		t.Fatalf("oldest transaction expected first, actual stack %s", open[0].Stack)
+	}
+
+	if !bytes.Contains(open[1].Stack, []byte("Test_Tracker")) {
+		t.Fatalf("stack of the caller expected, actual %s", open[1].Stack)
+	}
+
+	untrackFirst()
+	untrackFirst()
+
+	open = tracker.Open()
+	if len(open) != 1 {
+		t.Fatalf("expected 1 open transaction, actual %d", len(open))
+	}
+
+	untrackSecond()
+
+	if len(tracker.Open()) != 0 {
+		t.Fatal("expected no open transactions")
+	}
+}
+
+func Test_Tracker_Disabled(t *testing.T) {
+	t.Parallel()
+
+	tracker := leak.New(tx.ApplyBeginnerOptions())
+	if tracker != nil {
+		t.Fatal("tracker expected to be nil when tracking disabled")
+	}
+
+	tracker.Track(nil)()
+
+	if tracker.Open() != nil {
+		t.Fatal("disabled tracker expected to report nothing")
+	}
+}
@@ -0,0 +1,61 @@
+package nested
+
+import (
+	"context"
+	"database/sql"
+	"runtime/debug"
+
+	"github.com/amidgo/tx"
+	"github.com/amidgo/tx/internal/sqlctx"
+)
+
+// Stack returns stack of the caller if nested begin detection or transactions tracking enabled.
+func Stack(opts tx.BeginnerOptions) []byte {
+	if opts.NestedBegin == tx.NestedBeginAllow && !opts.TrackTransactions {
+		return nil
+	}
+
+	return debug.Stack()
+}
+
+// CheckBegin checks that ctx doesn't hold transaction on db before acquiring a new connection for begin.
+func CheckBegin(ctx context.Context, db *sql.DB, opts tx.BeginnerOptions) error {
+	held, ok := detect(ctx, db, opts)
+	if !ok {
+		return nil
+	}
+
+	if opts.NestedBegin == tx.NestedBeginFail {
+		return tx.ErrNestedBegin
+	}
+
+	warn(ctx, opts, held, "nested begin")
+
+	return nil
+}
+
+// CheckExecutor checks that ctx doesn't hold transaction on db before returning the pool as executor,
+// executor can't fail, so it only warns.
+func CheckExecutor(ctx context.Context, db *sql.DB, opts tx.BeginnerOptions) {
+	held, ok := detect(ctx, db, opts)
+	if !ok {
+		return
+	}
+
+	warn(ctx, opts, held, "nested executor")
+}
+
+func detect(ctx context.Context, db *sql.DB, opts tx.BeginnerOptions) (*sqlctx.Tx, bool) {
+	if opts.NestedBegin == tx.NestedBeginAllow {
+		return nil, false
+	}
+
+	return sqlctx.Held(ctx, db)
+}
+
+func warn(ctx context.Context, opts tx.BeginnerOptions, held *sqlctx.Tx, msg string) {
+	opts.NestedBeginLogger.WarnContext(ctx, msg+", transaction on the same pool is already held",
+		"held_tx_stack", string(held.Stack),
+		"stack", string(debug.Stack()),
+	)
+}
@@ -0,0 +1,113 @@
+package nested_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/amidgo/tx"
+	"github.com/amidgo/tx/internal/nested"
+	"github.com/amidgo/tx/internal/sqlctx"
+	"github.com/amidgo/tx/internal/txstate"
+)
+
+func Test_CheckBegin(t *testing.T) {
+	db := &sql.DB{}
+
+	txContext := sqlctx.WithTx(context.Background(),
+		&sqlctx.Tx{
+			DB:    db,
+			State: &txstate.Machine{},
+			Stack: []byte("held tx stack"),
+		},
+	)
+
+	t.Run("allow", func(t *testing.T) {
+		err := nested.CheckBegin(txContext, db, tx.ApplyBeginnerOptions())
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		opts := tx.ApplyBeginnerOptions(tx.DetectNestedBegin(tx.NestedBeginFail, nil))
+
+		err := nested.CheckBegin(txContext, db, opts)
+		if !errors.Is(err, tx.ErrNestedBegin) {
+			t.Fatalf("unexpected error, expected %s, actual %s", tx.ErrNestedBegin, err)
+		}
+
+		err = nested.CheckBegin(context.Background(), db, opts)
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		err = nested.CheckBegin(txContext, &sql.DB{}, opts)
+		if err != nil {
+			t.Fatalf("unexpected error on other db, %s", err)
+		}
+	})
+
+	t.Run("warn", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		opts := tx.ApplyBeginnerOptions(
+			tx.DetectNestedBegin(tx.NestedBeginWarn, slog.New(slog.NewTextHandler(buf, nil))),
+		)
+
+		err := nested.CheckBegin(txContext, db, opts)
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		requireWarning(t, buf.String())
+	})
+}
+
+func Test_CheckExecutor(t *testing.T) {
+	db := &sql.DB{}
+
+	txContext := sqlctx.WithTx(context.Background(),
+		&sqlctx.Tx{
+			DB:    db,
+			State: &txstate.Machine{},
+			Stack: []byte("held tx stack"),
+		},
+	)
+
+	buf := &bytes.Buffer{}
+
+	opts := tx.ApplyBeginnerOptions(
+		tx.DetectNestedBegin(tx.NestedBeginFail, slog.New(slog.NewTextHandler(buf, nil))),
+	)
+
+	nested.CheckExecutor(sqlctx.WithHeld(context.Background(), txContext, db), db, opts)
+
+	requireWarning(t, buf.String())
+}
+
+func Test_Stack(t *testing.T) {
+	if nested.Stack(tx.ApplyBeginnerOptions()) != nil {
+		t.Fatal("stack must not be recorded if detection disabled")
+	}
+
+	opts := tx.ApplyBeginnerOptions(tx.DetectNestedBegin(tx.NestedBeginWarn, nil))
+
+	if len(nested.Stack(opts)) == 0 {
+		t.Fatal("stack must be recorded if detection enabled")
+	}
+}
+
+func requireWarning(t *testing.T, log string) {
+	t.Helper()
+
+	for _, expected := range []string{"level=WARN", "held tx stack", "nested_test.go"} {
+		if !strings.Contains(log, expected) {
+			t.Fatalf("warning %q doesn't contain %q", log, expected)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package sqlctx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/amidgo/tx/internal/txstate"
+)
+
+var (
+	ErrNoTx = errors.New("transaction not found in context")
+	// ErrAmbiguousTx is returned by Current when ctx holds active transactions of several databases.
+	ErrAmbiguousTx = errors.New("transactions of several databases in context")
+)
+
+type (
+	txKey   struct{}
+	heldKey struct{}
+)
+
+// Tx is a database/sql transaction placed into context by one of the adapters,
+// adapters over the same *sql.DB share it.
+type Tx struct {
+	// DB is a pool the transaction began on, nil if the transaction was created externally.
+	DB *sql.DB
+	Tx *sql.Tx
+	// Native is an adapter specific transaction, e.g. *sql.Tx, *sqlx.Tx or bun.Tx.
+	Native any
+	State  *txstate.Machine
+	// Stack is a stack of the transaction begin, recorded only if nested begin detection or transactions tracking enabled.
+	Stack []byte
+}
+
+// chain links transactions placed into context, so transactions began on different databases
+// don't shadow each other.
+type chain struct {
+	tx     *Tx
+	parent *chain
+}
+
+func push(ctx context.Context, key any, tx *Tx) context.Context {
+	parent, _ := ctx.Value(key).(*chain)
+
+	return context.WithValue(ctx, key, &chain{tx: tx, parent: parent})
+}
+
+// find returns the innermost active transaction of key matching match.
+func find(ctx context.Context, key any, match func(tx *Tx) bool) (*Tx, bool) {
+	for c, _ := ctx.Value(key).(*chain); c != nil; c = c.parent {
+		if c.tx.State.Active() && match(c.tx) {
+			return c.tx, true
+		}
+	}
+
+	return nil, false
+}
+
+// WithTx places tx into ctx, transactions on other databases already placed into ctx stay visible.
+func WithTx(ctx context.Context, tx *Tx) context.Context {
+	return push(ctx, txKey{}, tx)
+}
+
+// FromContext returns the innermost active transaction began on db,
+// transaction with unknown DB matches any db.
+func FromContext(ctx context.Context, db *sql.DB) (*Tx, bool) {
+	return find(ctx, txKey{}, func(tx *Tx) bool {
+		return tx.DB == nil || tx.DB == db
+	})
+}
+
+// Current returns the innermost active transaction of ctx for callers that don't know db,
+// ErrAmbiguousTx is returned if ctx holds active transactions of several databases, e.g. inside RunMulti,
+// since picking one of them would silently write to the wrong database.
+func Current(ctx context.Context) (*Tx, error) {
+	var current *Tx
+
+	for c, _ := ctx.Value(txKey{}).(*chain); c != nil; c = c.parent {
+		if !c.tx.State.Active() {
+			continue
+		}
+
+		if current == nil {
+			current = c.tx
+
+			continue
+		}
+
+		if c.tx.DB != current.DB {
+			return nil, ErrAmbiguousTx
+		}
+	}
+
+	if current == nil {
+		return nil, ErrNoTx
+	}
+
+	return current, nil
+}
+
+// WithHeld marks ctx as a context of the caller that holds transaction of txContext began on db,
+// but doesn't use it, e.g. context passed to WithTx callback.
+func WithHeld(ctx, txContext context.Context, db *sql.DB) context.Context {
+	tx, ok := FromContext(txContext, db)
+	if !ok {
+		return ctx
+	}
+
+	return push(ctx, heldKey{}, tx)
+}
+
+// Held returns active transaction held by the caller on exactly the same db,
+// either placed into ctx or marked by WithHeld.
+func Held(ctx context.Context, db *sql.DB) (*Tx, bool) {
+	sameDB := func(tx *Tx) bool {
+		return tx.DB == db
+	}
+
+	for _, key := range []any{txKey{}, heldKey{}} {
+		tx, ok := find(ctx, key, sameDB)
+		if ok {
+			return tx, true
+		}
+	}
+
+	return nil, false
+}
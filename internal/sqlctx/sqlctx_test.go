@@ -0,0 +1,171 @@
+package sqlctx_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/amidgo/tx/internal/sqlctx"
+	"github.com/amidgo/tx/internal/txstate"
+)
+
+func Test_FromContext(t *testing.T) {
+	db, otherDB := &sql.DB{}, &sql.DB{}
+
+	ctx := context.Background()
+
+	_, ok := sqlctx.FromContext(ctx, db)
+	if ok {
+		t.Fatal("empty context must not contain tx")
+	}
+
+	state := &txstate.Machine{}
+
+	ctx = sqlctx.WithTx(ctx, &sqlctx.Tx{DB: db, State: state})
+
+	_, ok = sqlctx.FromContext(ctx, db)
+	if !ok {
+		t.Fatal("tx began on db expected")
+	}
+
+	_, ok = sqlctx.FromContext(ctx, otherDB)
+	if ok {
+		t.Fatal("tx began on other db must not be returned")
+	}
+
+	_ = state.Commit(func() error { return nil })
+
+	_, ok = sqlctx.FromContext(ctx, db)
+	if ok {
+		t.Fatal("committed tx must not be returned")
+	}
+}
+
+func Test_FromContext_UnknownDB(t *testing.T) {
+	ctx := sqlctx.WithTx(context.Background(), &sqlctx.Tx{State: &txstate.Machine{}})
+
+	_, ok := sqlctx.FromContext(ctx, &sql.DB{})
+	if !ok {
+		t.Fatal("tx with unknown db must match any db")
+	}
+}
+
+func Test_Current(t *testing.T) {
+	_, err := sqlctx.Current(context.Background())
+	if !errors.Is(err, sqlctx.ErrNoTx) {
+		t.Fatalf("empty context must not contain tx, actual %v", err)
+	}
+
+	state := &txstate.Machine{}
+
+	ctx := sqlctx.WithTx(context.Background(), &sqlctx.Tx{DB: &sql.DB{}, State: state})
+
+	_, err = sqlctx.Current(ctx)
+	if err != nil {
+		t.Fatalf("tx began on any db expected, actual %v", err)
+	}
+
+	_ = state.Rollback(func() error { return nil })
+
+	_, err = sqlctx.Current(ctx)
+	if !errors.Is(err, sqlctx.ErrNoTx) {
+		t.Fatalf("rolled back tx must not be returned, actual %v", err)
+	}
+}
+
+func Test_Held(t *testing.T) {
+	db := &sql.DB{}
+
+	state := &txstate.Machine{}
+
+	txContext := sqlctx.WithTx(context.Background(), &sqlctx.Tx{DB: db, State: state})
+
+	_, ok := sqlctx.Held(txContext, db)
+	if !ok {
+		t.Fatal("tx in ctx must be held")
+	}
+
+	_, ok = sqlctx.Held(txContext, &sql.DB{})
+	if ok {
+		t.Fatal("tx began on other db must not be held")
+	}
+
+	ctx := sqlctx.WithHeld(context.Background(), txContext, db)
+
+	_, ok = sqlctx.FromContext(ctx, db)
+	if ok {
+		t.Fatal("held ctx must not contain tx")
+	}
+
+	_, ok = sqlctx.Held(ctx, db)
+	if !ok {
+		t.Fatal("held ctx must hold tx")
+	}
+
+	_ = state.Rollback(func() error { return nil })
+
+	_, ok = sqlctx.Held(ctx, db)
+	if ok {
+		t.Fatal("rolled back tx must not be held")
+	}
+}
+
+func Test_Held_UnknownDB(t *testing.T) {
+	ctx := sqlctx.WithTx(context.Background(), &sqlctx.Tx{State: &txstate.Machine{}})
+
+	_, ok := sqlctx.Held(ctx, &sql.DB{})
+	if ok {
+		t.Fatal("tx with unknown db must not be held")
+	}
+}
+
+func Test_FromContext_Nested(t *testing.T) {
+	db, otherDB := &sql.DB{}, &sql.DB{}
+
+	tx := &sqlctx.Tx{DB: db, State: &txstate.Machine{}}
+	otherTx := &sqlctx.Tx{DB: otherDB, State: &txstate.Machine{}}
+
+	ctx := sqlctx.WithTx(sqlctx.WithTx(context.Background(), tx), otherTx)
+
+	actual, ok := sqlctx.FromContext(ctx, db)
+	if !ok || actual != tx {
+		t.Fatal("tx began on db must not be shadowed by tx on other db")
+	}
+
+	actual, ok = sqlctx.FromContext(ctx, otherDB)
+	if !ok || actual != otherTx {
+		t.Fatal("tx began on other db expected")
+	}
+
+	_, err := sqlctx.Current(ctx)
+	if !errors.Is(err, sqlctx.ErrAmbiguousTx) {
+		t.Fatalf("transactions of several databases must be ambiguous, actual %v", err)
+	}
+
+	_, ok = sqlctx.Held(sqlctx.WithHeld(ctx, ctx, db), db)
+	if !ok {
+		t.Fatal("tx began on db must be held")
+	}
+
+	_ = otherTx.State.Commit(func() error { return nil })
+
+	actual, err = sqlctx.Current(ctx)
+	if err != nil || actual != tx {
+		t.Fatalf("outer active tx expected after inner tx ended, actual %v", err)
+	}
+}
+
+func Test_Current_SameDB(t *testing.T) {
+	db := &sql.DB{}
+
+	outer := &sqlctx.Tx{DB: db, State: &txstate.Machine{}}
+	inner := &sqlctx.Tx{DB: db, State: &txstate.Machine{}}
+
+	ctx := sqlctx.WithTx(sqlctx.WithTx(context.Background(), outer), inner)
+
+	actual, err := sqlctx.Current(ctx)
+	if err != nil || actual != inner {
+		t.Fatalf("innermost tx of the same db expected, actual %v", err)
+	}
+}
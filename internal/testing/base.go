@@ -4,6 +4,7 @@ import (
 	context "context"
 	sql "database/sql"
 	"errors"
+	"sync"
 	"testing"
 
 	"github.com/google/uuid"
@@ -234,3 +235,42 @@ func AssertBeginError(
 		t.Fatal("assert beginner.BeginTx tx is nil on error, unexpected non nil tx")
 	}
 }
+
+func AssertTxDone(
+	t *testing.T,
+	beginner tx.Beginner,
+	transaction tx.Tx,
+) {
+	require.Equal(t, tx.StateActive, tx.State(transaction))
+
+	wg := sync.WaitGroup{}
+
+	for range 8 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for range 100 {
+				_ = txEnabled(transaction.Context(), beginner)
+				_ = tx.State(transaction)
+			}
+		}()
+	}
+
+	err := transaction.Commit()
+	require.NoError(t, err)
+
+	wg.Wait()
+
+	require.Equal(t, tx.StateCommitted, tx.State(transaction))
+	require.False(t, txEnabled(transaction.Context(), beginner))
+
+	err = transaction.Commit()
+	require.ErrorIs(t, err, tx.ErrTxDone)
+
+	err = transaction.Rollback()
+	require.ErrorIs(t, err, tx.ErrTxDone)
+
+	require.Equal(t, tx.StateCommitted, tx.State(transaction))
+}
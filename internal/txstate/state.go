@@ -0,0 +1,70 @@
+package txstate
+
+import (
+	"database/sql"
+	"sync/atomic"
+)
+
+// State mirrors tx.TxState, the values must be kept in sync.
+type State int32
+
+const (
+	Active State = iota
+	Committing
+	Committed
+	RolledBack
+	Failed
+)
+
+// Machine is a race free lifecycle of a single transaction.
+// Zero value is an active transaction.
+type Machine struct {
+	state atomic.Int32
+}
+
+func (m *Machine) State() State {
+	return State(m.state.Load())
+}
+
+func (m *Machine) Active() bool {
+	return m.State() == Active
+}
+
+// Commit moves transaction from Active to Committing state and calls commit.
+// Transaction ends up Committed if commit succeeded, Failed otherwise.
+// If transaction is not active commit is not called and sql.ErrTxDone returned.
+func (m *Machine) Commit(commit func() error) error {
+	swapped := m.state.CompareAndSwap(int32(Active), int32(Committing))
+	if !swapped {
+		return sql.ErrTxDone
+	}
+
+	err := commit()
+	if err != nil {
+		m.state.Store(int32(Failed))
+
+		return err
+	}
+
+	m.state.Store(int32(Committed))
+
+	return nil
+}
+
+// Rollback moves Active or Failed transaction to RolledBack state and calls rollback.
+// In other states rollback is not called and sql.ErrTxDone returned.
+func (m *Machine) Rollback(rollback func() error) error {
+	for {
+		state := m.state.Load()
+
+		if State(state) != Active && State(state) != Failed {
+			return sql.ErrTxDone
+		}
+
+		if m.state.CompareAndSwap(state, int32(RolledBack)) {
+			break
+		}
+	}
+
+	return rollback()
+}
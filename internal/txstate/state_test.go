@@ -0,0 +1,109 @@
+package txstate_test
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/amidgo/tx/internal/txstate"
+)
+
+func Test_Machine_Commit(t *testing.T) {
+	m := &txstate.Machine{}
+
+	if !m.Active() {
+		t.Fatal("zero machine must be active")
+	}
+
+	err := m.Commit(func() error { return nil })
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+
+	if m.State() != txstate.Committed {
+		t.Fatalf("unexpected state, expected %d, actual %d", txstate.Committed, m.State())
+	}
+
+	err = m.Commit(func() error {
+		t.Fatal("unexpected commit call")
+
+		return nil
+	})
+	if !errors.Is(err, sql.ErrTxDone) {
+		t.Fatalf("unexpected error, expected %s, actual %s", sql.ErrTxDone, err)
+	}
+
+	err = m.Rollback(func() error {
+		t.Fatal("unexpected rollback call")
+
+		return nil
+	})
+	if !errors.Is(err, sql.ErrTxDone) {
+		t.Fatalf("unexpected error, expected %s, actual %s", sql.ErrTxDone, err)
+	}
+}
+
+func Test_Machine_RollbackAfterFailedCommit(t *testing.T) {
+	m := &txstate.Machine{}
+
+	errCommit := errors.New("commit")
+
+	err := m.Commit(func() error { return errCommit })
+	if !errors.Is(err, errCommit) {
+		t.Fatalf("unexpected error, expected %s, actual %s", errCommit, err)
+	}
+
+	if m.State() != txstate.Failed {
+		t.Fatalf("unexpected state, expected %d, actual %d", txstate.Failed, m.State())
+	}
+
+	err = m.Rollback(func() error { return nil })
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+
+	if m.State() != txstate.RolledBack {
+		t.Fatalf("unexpected state, expected %d, actual %d", txstate.RolledBack, m.State())
+	}
+}
+
+func Test_Machine_ConcurrentCommitRollback(t *testing.T) {
+	const goroutines = 64
+
+	m := &txstate.Machine{}
+
+	var (
+		calls atomic.Int32
+		wg    sync.WaitGroup
+	)
+
+	for i := range goroutines {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			call := func() error {
+				calls.Add(1)
+
+				return nil
+			}
+
+			if i%2 == 0 {
+				_ = m.Commit(call)
+			} else {
+				_ = m.Rollback(call)
+			}
+
+			_ = m.Active()
+		}()
+	}
+
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Fatalf("expected exactly one commit or rollback call, actual %d", calls.Load())
+	}
+}
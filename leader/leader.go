@@ -0,0 +1,192 @@
+// Package leader implements leader election with Postgres session advisory locks.
+package leader
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	pgxtx "github.com/amidgo/tx/pgx"
+)
+
+var ErrLeadershipLost = errors.New("leader: leadership lost")
+
+const (
+	defaultRetryInterval = 5 * time.Second
+	defaultCheckInterval = time.Second
+)
+
+type Option func(*Elector)
+
+// WithRetryInterval sets pause between attempts to become leader, 5 seconds by default.
+func WithRetryInterval(interval time.Duration) Option {
+	return func(e *Elector) {
+		e.retryInterval = interval
+	}
+}
+
+// WithCheckInterval sets interval of leader lock and connection checks, 1 second by default.
+func WithCheckInterval(interval time.Duration) Option {
+	return func(e *Elector) {
+		e.checkInterval = interval
+	}
+}
+
+// WithLogger sets logger of Run errors, slog.Default by default.
+func WithLogger(logger *slog.Logger) Option {
+	return func(e *Elector) {
+		e.logger = logger
+	}
+}
+
+// Elector elects one leader among electors with the same key over the same database,
+// leader holds session advisory lock of the key on a connection pinned from db.
+type Elector struct {
+	db  *sql.DB
+	key int64
+
+	leader     atomic.Bool
+	leadership chan bool
+
+	retryInterval time.Duration
+	checkInterval time.Duration
+	logger        *slog.Logger
+}
+
+// New creates Elector over session advisory lock of pgxtx.AdvisoryKey of key.
+func New(db *sql.DB, key string, opts ...Option) *Elector {
+	e := &Elector{
+		db:            db,
+		key:           pgxtx.AdvisoryKey(key),
+		leadership:    make(chan bool, 1),
+		retryInterval: defaultRetryInterval,
+		checkInterval: defaultCheckInterval,
+		logger:        slog.Default(),
+	}
+
+	for _, op := range opts {
+		op(e)
+	}
+
+	return e
+}
+
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Leadership returns channel of leadership changes, true when leadership is taken, false when it is lost.
+// Only the latest change is kept for slow reader.
+func (e *Elector) Leadership() <-chan bool {
+	return e.leadership
+}
+
+// Run campaigns for leadership until ctx is done and runs lead every time leadership is taken,
+// lead context is cancelled when the lock or its connection is lost.
+// Leadership is released when lead returns, campaign continues after retry interval.
+// Run must not be called concurrently.
+func (e *Elector) Run(ctx context.Context, lead func(leaderCtx context.Context) error) error {
+	for {
+		err := e.campaign(ctx, lead)
+		if err != nil && ctx.Err() == nil {
+			e.logger.ErrorContext(ctx, "leader election failed", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(e.retryInterval):
+		}
+	}
+}
+
+func (e *Elector) campaign(ctx context.Context, lead func(leaderCtx context.Context) error) error {
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	var locked bool
+
+	err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", e.key).Scan(&locked)
+	if err != nil || !locked {
+		return err
+	}
+
+	defer e.unlock(conn)
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	e.setLeader(true)
+	defer e.setLeader(false)
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- lead(leaderCtx)
+	}()
+
+	ticker := time.NewTicker(e.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-done:
+			return err
+		case <-ticker.C:
+			held, err := e.held(ctx, conn)
+			if err == nil && held {
+				continue
+			}
+
+			cancel()
+
+			return errors.Join(ErrLeadershipLost, err, <-done)
+		}
+	}
+}
+
+// held checks the lock is still granted to the connection session.
+func (e *Elector) held(ctx context.Context, conn *sql.Conn) (bool, error) {
+	var held bool
+
+	err := conn.QueryRowContext(ctx,
+		`SELECT EXISTS (
+			SELECT 1 FROM pg_locks
+			WHERE locktype = 'advisory' AND pid = pg_backend_pid() AND granted AND objsubid = 1
+			AND ((classid::bigint << 32) | objid::bigint) = $1
+		)`,
+		e.key,
+	).Scan(&held)
+
+	return held, err
+}
+
+// unlock releases the lock, connection is discarded if the lock might remain held.
+func (e *Elector) unlock(conn *sql.Conn) {
+	_, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", e.key)
+	if err == nil {
+		return
+	}
+
+	_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+}
+
+// setLeader is called only by Run goroutine, so buffered channel never blocks.
+func (e *Elector) setLeader(leader bool) {
+	e.leader.Store(leader)
+
+	select {
+	case <-e.leadership:
+	default:
+	}
+
+	e.leadership <- leader
+}
@@ -0,0 +1,146 @@
+package leader_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	postgrescontainer "github.com/amidgo/containers/postgres"
+	"github.com/amidgo/containers/postgres/migrations"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amidgo/tx/internal/reusable"
+	"github.com/amidgo/tx/leader"
+)
+
+type instance struct {
+	elector *leader.Elector
+	cancel  context.CancelFunc
+	leads   atomic.Int32
+	stopped chan struct{}
+}
+
+func runInstance(elector *leader.Elector) *instance {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	inst := &instance{
+		elector: elector,
+		cancel:  cancel,
+		stopped: make(chan struct{}),
+	}
+
+	go func() {
+		defer close(inst.stopped)
+
+		_ = inst.elector.Run(ctx, func(leaderCtx context.Context) error {
+			inst.leads.Add(1)
+
+			<-leaderCtx.Done()
+
+			return nil
+		})
+	}()
+
+	return inst
+}
+
+func (i *instance) stop() {
+	i.cancel()
+	<-i.stopped
+}
+
+func waitLeader(t *testing.T, instances ...*instance) *instance {
+	var leaderInstance *instance
+
+	require.Eventually(t,
+		func() bool {
+			leaders := 0
+
+			for _, inst := range instances {
+				if inst.elector.IsLeader() {
+					leaders++
+					leaderInstance = inst
+				}
+			}
+
+			return leaders == 1
+		},
+		5*time.Second,
+		10*time.Millisecond,
+	)
+
+	return leaderInstance
+}
+
+func Test_Elector(t *testing.T) {
+	t.Parallel()
+
+	db := postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil)
+
+	opts := []leader.Option{
+		leader.WithRetryInterval(20 * time.Millisecond),
+		leader.WithCheckInterval(20 * time.Millisecond),
+	}
+
+	first := runInstance(leader.New(db, "cron", opts...))
+	second := runInstance(leader.New(db, "cron", opts...))
+
+	t.Cleanup(first.stop)
+	t.Cleanup(second.stop)
+
+	current := waitLeader(t, first, second)
+	require.True(t, <-current.elector.Leadership())
+
+	// stays the only leader
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, current, waitLeader(t, first, second))
+	require.Equal(t, int32(1), current.leads.Load())
+
+	current.stop()
+	require.False(t, current.elector.IsLeader())
+	require.False(t, <-current.elector.Leadership())
+
+	next := first
+	if current == first {
+		next = second
+	}
+
+	require.Equal(t, next, waitLeader(t, next))
+}
+
+func Test_Elector_ConnectionLost(t *testing.T) {
+	t.Parallel()
+
+	db := postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil)
+
+	opts := []leader.Option{
+		leader.WithRetryInterval(20 * time.Millisecond),
+		leader.WithCheckInterval(20 * time.Millisecond),
+	}
+
+	first := runInstance(leader.New(db, "connection", opts...))
+	second := runInstance(leader.New(db, "connection", opts...))
+
+	t.Cleanup(first.stop)
+	t.Cleanup(second.stop)
+
+	waitLeader(t, first, second)
+
+	_, err := db.Exec(`
+		SELECT pg_terminate_backend(pid) FROM pg_locks
+		WHERE locktype = 'advisory' AND granted AND pid <> pg_backend_pid()
+		AND database = (SELECT oid FROM pg_database WHERE datname = current_database())
+	`)
+	require.NoError(t, err)
+
+	require.Eventually(t,
+		func() bool {
+			return first.leads.Load()+second.leads.Load() == 2
+		},
+		5*time.Second,
+		10*time.Millisecond,
+	)
+
+	waitLeader(t, first, second)
+}
@@ -0,0 +1,22 @@
+package tx
+
+import "time"
+
+// OpenTransaction is a transaction began by adapter beginner with TrackTransactions
+// and neither committed nor rolled back yet.
+type OpenTransaction struct {
+	// Stack is a stack of the transaction begin.
+	Stack   []byte
+	BeganAt time.Time
+	// Age is time passed since BeganAt when open transactions were requested.
+	Age time.Duration
+}
+
+// TrackTransactions makes adapter beginners record every open transaction with its begin stack,
+// open transactions are reported by OpenTransactions method of the beginner.
+// Recording stack on every begin is expensive, so it is meant for tests and debugging of leaks.
+func TrackTransactions() BeginnerOption {
+	return func(o *BeginnerOptions) {
+		o.TrackTransactions = true
+	}
+}
@@ -0,0 +1,261 @@
+package tx
+
+import (
+	"container/heap"
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+)
+
+type priorityKey struct{}
+
+// ContextWithPriority sets priority of transactions begun with ctx by LimitedBeginner,
+// higher priority is served first, 0 by default.
+func ContextWithPriority(ctx context.Context, priority int) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+func PriorityFromContext(ctx context.Context) int {
+	priority, _ := ctx.Value(priorityKey{}).(int)
+
+	return priority
+}
+
+type LimiterStats struct {
+	// Open is number of open transactions.
+	Open int
+	// Queued is number of callers waiting for a free slot.
+	Queued int
+	// Waits is number of callers that waited for a free slot.
+	Waits uint64
+	// WaitTime is total time callers waited for a free slot, including cancelled waits.
+	WaitTime time.Duration
+}
+
+var _ Beginner = (*LimitedBeginner)(nil)
+
+// LimitedBeginner caps number of concurrently open transactions by limit,
+// excess callers wait in queue ordered by priority from ctx, in arrival order for equal priorities.
+// Waiting is interrupted by ctx cancellation.
+// Transaction holds its slot until Commit or Rollback,
+// so caller that holds a transaction must not begin another one with the same LimitedBeginner.
+type LimitedBeginner struct {
+	beginner Beginner
+	limit    int
+
+	mu       sync.Mutex
+	open     int
+	queue    waitQueue
+	seq      uint64
+	waits    uint64
+	waitTime time.Duration
+}
+
+func NewLimitedBeginner(beginner Beginner, limit int) *LimitedBeginner {
+	return &LimitedBeginner{
+		beginner: beginner,
+		limit:    limit,
+	}
+}
+
+func (l *LimitedBeginner) Begin(ctx context.Context) (Tx, error) {
+	return l.begin(ctx, l.beginner.Begin)
+}
+
+func (l *LimitedBeginner) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	return l.begin(ctx, func(ctx context.Context) (Tx, error) {
+		return l.beginner.BeginTx(ctx, opts)
+	})
+}
+
+func (l *LimitedBeginner) begin(ctx context.Context, begin func(ctx context.Context) (Tx, error)) (Tx, error) {
+	err := l.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := begin(ctx)
+	if err != nil {
+		l.release()
+
+		return nil, err
+	}
+
+	return &limitedTx{Tx: tx, release: l.release}, nil
+}
+
+func (l *LimitedBeginner) Stats() LimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return LimiterStats{
+		Open:     l.open,
+		Queued:   l.queue.Len(),
+		Waits:    l.waits,
+		WaitTime: l.waitTime,
+	}
+}
+
+func (l *LimitedBeginner) Driver() Driver {
+	driver, _ := getDriver(l.beginner)
+
+	return driver
+}
+
+func (l *LimitedBeginner) RetryBudget() *RetryBudget {
+	return getRetryBudget(l.beginner)
+}
+
+func (l *LimitedBeginner) TxEnabled(ctx context.Context) bool {
+	return txEnabled(ctx, l.beginner)
+}
+
+func (l *LimitedBeginner) tracker() (runTracker, bool) {
+	return getRunTracker(l.beginner)
+}
+
+func (l *LimitedBeginner) acquire(ctx context.Context) error {
+	l.mu.Lock()
+
+	if l.open < l.limit && l.queue.Len() == 0 {
+		l.open++
+		l.mu.Unlock()
+
+		return nil
+	}
+
+	w := &waiter{
+		priority: PriorityFromContext(ctx),
+		seq:      l.seq,
+		ready:    make(chan struct{}),
+	}
+
+	l.seq++
+	l.waits++
+	heap.Push(&l.queue, w)
+
+	l.mu.Unlock()
+
+	start := time.Now()
+
+	select {
+	case <-w.ready:
+		l.addWaitTime(time.Since(start))
+
+		return nil
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.waitTime += time.Since(start)
+
+	select {
+	case <-w.ready:
+		// slot was handed over concurrently with cancellation, pass it on
+		l.releaseLocked()
+	default:
+		heap.Remove(&l.queue, w.index)
+	}
+
+	return ctx.Err()
+}
+
+func (l *LimitedBeginner) addWaitTime(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.waitTime += d
+}
+
+func (l *LimitedBeginner) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.releaseLocked()
+}
+
+// releaseLocked hands the slot over to the first waiter or frees it.
+func (l *LimitedBeginner) releaseLocked() {
+	if l.queue.Len() == 0 {
+		l.open--
+
+		return
+	}
+
+	w := heap.Pop(&l.queue).(*waiter)
+	close(w.ready)
+}
+
+type limitedTx struct {
+	Tx
+	release func()
+	once    sync.Once
+}
+
+func (l *limitedTx) Commit() error {
+	defer l.once.Do(l.release)
+
+	return l.Tx.Commit()
+}
+
+func (l *limitedTx) Rollback() error {
+	defer l.once.Do(l.release)
+
+	return l.Tx.Rollback()
+}
+
+func (l *limitedTx) State() TxState {
+	return State(l.Tx)
+}
+
+func (l *limitedTx) Driver() Driver {
+	driver, _ := getDriver(l.Tx)
+
+	return driver
+}
+
+type waiter struct {
+	priority int
+	seq      uint64
+	index    int
+	ready    chan struct{}
+}
+
+type waitQueue []*waiter
+
+func (q waitQueue) Len() int {
+	return len(q)
+}
+
+func (q waitQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+
+	return q[i].seq < q[j].seq
+}
+
+func (q waitQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *waitQueue) Push(x any) {
+	w := x.(*waiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *waitQueue) Pop() any {
+	old := *q
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+
+	return w
+}
@@ -0,0 +1,116 @@
+package tx_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/amidgo/tx"
+	txmocks "github.com/amidgo/tx/mocks"
+)
+
+func Test_LimitedBeginner_Priority(t *testing.T) {
+	ctx := context.Background()
+
+	limiter := tx.NewLimitedBeginner(
+		txmocks.JoinBeginners(
+			txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil),
+			txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil),
+			txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil),
+		)(t),
+		1,
+	)
+
+	holder, err := limiter.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("begin holder tx, %+v", err)
+	}
+
+	served := make(chan string, 2)
+
+	runQueued := func(name string, priority int, queued int) {
+		go func() {
+			_ = tx.Run(tx.ContextWithPriority(ctx, priority), limiter,
+				func(context.Context) error {
+					served <- name
+
+					return nil
+				},
+				nil,
+			)
+		}()
+
+		waitLimiterStats(t, limiter, func(stats tx.LimiterStats) bool { return stats.Queued == queued })
+	}
+
+	runQueued("batch", 0, 1)
+	runQueued("api", 10, 2)
+
+	stats := limiter.Stats()
+	if stats.Open != 1 || stats.Waits != 2 {
+		t.Fatalf("unexpected stats, %+v", stats)
+	}
+
+	err = holder.Commit()
+	if err != nil {
+		t.Fatalf("commit holder tx, %+v", err)
+	}
+
+	if first, second := <-served, <-served; first != "api" || second != "batch" {
+		t.Fatalf("unexpected serving order, %s, %s", first, second)
+	}
+
+	waitLimiterStats(t, limiter, func(stats tx.LimiterStats) bool {
+		return stats.Open == 0 && stats.Queued == 0 && stats.WaitTime > 0
+	})
+}
+
+func Test_LimitedBeginner_Canceled(t *testing.T) {
+	limiter := tx.NewLimitedBeginner(
+		txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollback(nil), nil)(t),
+		1,
+	)
+
+	holder, err := limiter.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("begin holder tx, %+v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = limiter.BeginTx(ctx, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, actual %+v", err)
+	}
+
+	stats := limiter.Stats()
+	if stats.Open != 1 || stats.Queued != 0 || stats.Waits != 1 {
+		t.Fatalf("unexpected stats, %+v", stats)
+	}
+
+	err = holder.Rollback()
+	if err != nil {
+		t.Fatalf("rollback holder tx, %+v", err)
+	}
+
+	stats = limiter.Stats()
+	if stats.Open != 0 {
+		t.Fatalf("slot expected to be released, %+v", stats)
+	}
+}
+
+func waitLimiterStats(t *testing.T, limiter *tx.LimitedBeginner, ready func(stats tx.LimiterStats) bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for !ready(limiter.Stats()) {
+		if time.Now().After(deadline) {
+			t.Fatalf("limiter stats not reached, %+v", limiter.Stats())
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
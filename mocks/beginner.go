@@ -7,6 +7,7 @@ import (
 	"sync/atomic"
 
 	"github.com/amidgo/tx"
+	"github.com/amidgo/tx/internal/txstate"
 )
 
 var _ tx.Beginner = (*Beginner)(nil)
@@ -40,9 +41,9 @@ func (b *Beginner) TxEnabled(ctx context.Context) bool {
 }
 
 func txEnabled(ctx context.Context) bool {
-	_, ok := ctx.Value(txKey{}).(mockTx)
+	state, ok := ctx.Value(txKey{}).(*txstate.Machine)
 
-	return ok
+	return ok && state.Active()
 }
 
 type BeginnerMock func(t testReporter) *Beginner
@@ -152,7 +153,7 @@ func (b *beginAndReturnTx) begin(ctx context.Context) (tx.Tx, error) {
 		b.t.Fatal("unexpected call, beginner.Begin called more than once")
 	}
 
-	b.tx.ctx = startTx(ctx)
+	b.tx.ctx = startTx(ctx, &b.tx.state)
 
 	return b.tx, nil
 }
@@ -203,7 +204,7 @@ func (b *beginTxAndReturnTx) beginTx(ctx context.Context, opts *sql.TxOptions) (
 
 	sqlOptsEqual(b.t, b.expectedOpts, opts)
 
-	b.tx.ctx = startTx(ctx)
+	b.tx.ctx = startTx(ctx, &b.tx.state)
 
 	return b.tx, nil
 }
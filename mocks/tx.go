@@ -2,16 +2,16 @@ package txmocks
 
 import (
 	"context"
-	"sync"
 	"sync/atomic"
+
+	"github.com/amidgo/tx"
+	"github.com/amidgo/tx/internal/txstate"
 )
 
 type txKey struct{}
 
-type mockTx struct{}
-
-func startTx(ctx context.Context) context.Context {
-	return context.WithValue(ctx, txKey{}, mockTx{})
+func startTx(ctx context.Context, state *txstate.Machine) context.Context {
+	return context.WithValue(ctx, txKey{}, state)
 }
 
 type txAsserter interface {
@@ -21,39 +21,58 @@ type txAsserter interface {
 }
 
 type Tx struct {
-	once sync.Once
-	asrt txAsserter
-	ctx  context.Context
+	asrt  txAsserter
+	ctx   context.Context
+	state txstate.Machine
 }
 
 func newTransaction(t testReporter, asrt txAsserter) *Tx {
 	t.Cleanup(asrt.assert)
 
-	ctx := startTx(context.Background())
+	tx := &Tx{asrt: asrt}
+	tx.ctx = startTx(context.Background(), &tx.state)
 
-	return &Tx{asrt: asrt, ctx: ctx}
+	return tx
 }
 
+// Commit and Rollback always reach the asserter, even if the transaction is already done,
+// so the asserter reports an unexpected call instead of silent tx.ErrTxDone.
 func (t *Tx) Commit() error {
-	t.clearTx()
+	called := false
+
+	err := t.state.Commit(func() error {
+		called = true
+
+		return t.asrt.commit()
+	})
+	if !called {
+		return t.asrt.commit()
+	}
 
-	return t.asrt.commit()
+	return err
 }
 
 func (t *Tx) Rollback() error {
-	t.clearTx()
+	called := false
 
-	return t.asrt.rollback()
+	err := t.state.Rollback(func() error {
+		called = true
+
+		return t.asrt.rollback()
+	})
+	if !called {
+		return t.asrt.rollback()
+	}
+
+	return err
 }
 
 func (t *Tx) Context() context.Context {
 	return t.ctx
 }
 
-func (t *Tx) clearTx() {
-	t.once.Do(func() {
-		t.ctx = context.WithValue(t.ctx, txKey{}, nil)
-	})
+func (t *Tx) State() tx.TxState {
+	return tx.TxState(t.state.State())
 }
 
 type TxMock func(t testReporter) *Tx
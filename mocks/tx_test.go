@@ -4,6 +4,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/amidgo/tx"
 	txmocks "github.com/amidgo/tx/mocks"
 )
 
@@ -165,3 +166,17 @@ func Test_Transaction_ExpectRollbackAfterFailedCommit_RollbackCalledTwice(t *tes
 	tx.Rollback()
 	tx.Rollback()
 }
+
+func Test_Transaction_State(t *testing.T) {
+	testReporter := newMockTestReporter(t, "")
+
+	mockTx := txmocks.ExpectCommit(testReporter)
+
+	requireEqual(t, tx.StateActive, tx.State(mockTx))
+
+	err := mockTx.Commit()
+	requireNoError(t, err)
+
+	requireEqual(t, tx.StateCommitted, tx.State(mockTx))
+	requireFalse(t, txmocks.TxEnabled().Matches(mockTx.Context()))
+}
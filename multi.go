@@ -0,0 +1,160 @@
+package tx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+var ErrPartialCommit = errors.New("partial commit")
+
+// PartialCommitError is returned by RunMulti when commit failed after some transactions were committed.
+type PartialCommitError struct {
+	// Committed holds indexes of the beginners whose transactions were committed.
+	Committed []int
+	// Failed is index of the beginner whose commit failed, transactions after it were rolled back.
+	Failed int
+	Err    error
+}
+
+func (e *PartialCommitError) Error() string {
+	return fmt.Sprintf("%s, committed %v, failed %d, %s", ErrPartialCommit, e.Committed, e.Failed, e.Err)
+}
+
+func (e *PartialCommitError) Unwrap() []error {
+	return []error{ErrPartialCommit, e.Err}
+}
+
+// RunMulti runs withTx with transactions of every beginner in txContext, best effort, without atomicity guarantee.
+// Transactions begin and commit in order of beginners,
+// failed commit of not the first transaction reported by *PartialCommitError.
+// Drivers of all beginners classify errors of the group, PartialCommitError is never retried.
+func RunMulti(
+	ctx context.Context,
+	beginners []Beginner,
+	withTx func(txContext context.Context) error,
+	txOpts *sql.TxOptions,
+	opts ...Option,
+) error {
+	return txPipelineExec(
+		ctx,
+		multiBeginner(beginners),
+		withTx,
+		txOpts,
+		opts...,
+	)()
+}
+
+type multiBeginner []Beginner
+
+func (m multiBeginner) Begin(ctx context.Context) (Tx, error) {
+	return m.begin(ctx, func(beginner Beginner, ctx context.Context) (Tx, error) {
+		return beginner.Begin(ctx)
+	})
+}
+
+func (m multiBeginner) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	return m.begin(ctx, func(beginner Beginner, ctx context.Context) (Tx, error) {
+		return beginner.BeginTx(ctx, opts)
+	})
+}
+
+func (m multiBeginner) begin(
+	ctx context.Context,
+	begin func(beginner Beginner, ctx context.Context) (Tx, error),
+) (Tx, error) {
+	multi := &multiTx{
+		ctx: ctx,
+		txs: make([]Tx, 0, len(m)),
+	}
+
+	for i, beginner := range m {
+		tx, err := begin(beginner, multi.ctx)
+		if err != nil {
+			_ = multi.Rollback()
+
+			return nil, fmt.Errorf("begin on beginner %d, %w", i, err)
+		}
+
+		multi.txs = append(multi.txs, tx)
+		multi.ctx = tx.Context()
+	}
+
+	return multi, nil
+}
+
+func (m multiBeginner) Driver() Driver {
+	drivers := make(multiDriver, 0, len(m))
+
+	for _, beginner := range m {
+		driver, ok := getDriver(beginner)
+		if ok && driver != nil {
+			drivers = append(drivers, driver)
+		}
+	}
+
+	return drivers
+}
+
+type multiDriver []Driver
+
+func (m multiDriver) Error(err error) error {
+	for _, driver := range m {
+		err = driver.Error(err)
+	}
+
+	return err
+}
+
+type multiTx struct {
+	ctx       context.Context
+	txs       []Tx
+	committed int
+}
+
+func (m *multiTx) Context() context.Context {
+	return m.ctx
+}
+
+func (m *multiTx) Commit() error {
+	for i := m.committed; i < len(m.txs); i++ {
+		err := m.txs[i].Commit()
+		if err == nil {
+			m.committed++
+
+			continue
+		}
+
+		if m.committed == 0 {
+			return err
+		}
+
+		committed := make([]int, m.committed)
+		for j := range committed {
+			committed[j] = j
+		}
+
+		return &PartialCommitError{
+			Committed: committed,
+			Failed:    i,
+			Err:       err,
+		}
+	}
+
+	return nil
+}
+
+// Rollback rolls back transactions that are not committed.
+func (m *multiTx) Rollback() error {
+	errs := make([]error, 0)
+
+	for i := len(m.txs) - 1; i >= m.committed; i-- {
+		err := m.txs[i].Rollback()
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
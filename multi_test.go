@@ -0,0 +1,203 @@
+package tx_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/amidgo/tx"
+	txmocks "github.com/amidgo/tx/mocks"
+)
+
+type runMultiTest struct {
+	Name             string
+	Beginners        []txmocks.BeginnerMock
+	Opts             []tx.Option
+	WithTx           func(t *testing.T, ctx context.Context) error
+	ExpectedErrors   []error
+	UnexpectedErrors []error
+}
+
+func (r *runMultiTest) Test(t *testing.T) {
+	beginners := make([]tx.Beginner, len(r.Beginners))
+	for i, beginnerMock := range r.Beginners {
+		beginners[i] = beginnerMock(t)
+	}
+
+	err := tx.RunMulti(context.Background(), beginners,
+		func(txContext context.Context) error {
+			return r.WithTx(t, txContext)
+		},
+		nil,
+		r.Opts...,
+	)
+
+	if len(r.ExpectedErrors) == 0 && err != nil {
+		t.Fatalf("expected no error, actual %+v", err)
+	}
+
+	for _, expectedErr := range r.ExpectedErrors {
+		if !errors.Is(err, expectedErr) {
+			t.Fatalf("unexpected error, expect %+v, actual %+v", expectedErr, err)
+		}
+	}
+
+	for _, unexpectedErr := range r.UnexpectedErrors {
+		if errors.Is(err, unexpectedErr) {
+			t.Fatalf("unexpected error, unexpect %+v, actual %+v", unexpectedErr, err)
+		}
+	}
+}
+
+func Test_RunMulti(t *testing.T) {
+	success := func(t *testing.T, ctx context.Context) error {
+		checkTxEnabled(t, ctx)
+
+		return nil
+	}
+
+	tests := []*runMultiTest{
+		{
+			Name: "all committed",
+			Beginners: []txmocks.BeginnerMock{
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil),
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil),
+			},
+			WithTx: success,
+		},
+		{
+			Name: "failed withTx, all rolled back",
+			Beginners: []txmocks.BeginnerMock{
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollback(nil), nil),
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollback(nil), nil),
+			},
+			WithTx:           func(*testing.T, context.Context) error { return io.ErrUnexpectedEOF },
+			ExpectedErrors:   []error{io.ErrUnexpectedEOF},
+			UnexpectedErrors: []error{tx.ErrCommit},
+		},
+		{
+			Name: "failed second begin, first rolled back",
+			Beginners: []txmocks.BeginnerMock{
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollback(nil), nil),
+				txmocks.ExpectBeginTxAndReturnError(io.ErrUnexpectedEOF, nil),
+			},
+			WithTx:           success,
+			ExpectedErrors:   []error{tx.ErrBeginTx, io.ErrUnexpectedEOF},
+			UnexpectedErrors: []error{tx.ErrCommit},
+		},
+		{
+			Name: "failed first commit, nothing committed",
+			Beginners: []txmocks.BeginnerMock{
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollbackAfterFailedCommit(io.ErrUnexpectedEOF), nil),
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollback(nil), nil),
+			},
+			WithTx:           success,
+			ExpectedErrors:   []error{tx.ErrCommit, io.ErrUnexpectedEOF},
+			UnexpectedErrors: []error{tx.ErrPartialCommit},
+		},
+		{
+			Name: "failed second commit, partial commit",
+			Beginners: []txmocks.BeginnerMock{
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil),
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollbackAfterFailedCommit(io.ErrUnexpectedEOF), nil),
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollback(nil), nil),
+			},
+			WithTx:         success,
+			ExpectedErrors: []error{tx.ErrCommit, tx.ErrPartialCommit, io.ErrUnexpectedEOF},
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, tst.Test)
+	}
+}
+
+func Test_RunMulti_PartialCommitError(t *testing.T) {
+	err := tx.RunMulti(context.Background(),
+		[]tx.Beginner{
+			txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil)(t),
+			txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil)(t),
+			txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollbackAfterFailedCommit(io.ErrUnexpectedEOF), nil)(t),
+		},
+		func(context.Context) error { return nil },
+		nil,
+	)
+
+	var partialErr *tx.PartialCommitError
+
+	if !errors.As(err, &partialErr) {
+		t.Fatalf("expected partial commit error, actual %+v", err)
+	}
+
+	if !slices.Equal(partialErr.Committed, []int{0, 1}) || partialErr.Failed != 2 {
+		t.Fatalf("unexpected partial commit, committed %v, failed %d", partialErr.Committed, partialErr.Failed)
+	}
+}
+
+func Test_RunMulti_Driver(t *testing.T) {
+	t.Run("group serialization retried", func(t *testing.T) {
+		beginners := []tx.Beginner{
+			tx.BeginnerWithDriver(
+				txmocks.JoinBeginners(
+					txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollback(nil), nil),
+					txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil),
+				)(t),
+				txmocks.ExpectDriverError(errors.Is, io.ErrUnexpectedEOF, tx.ErrSerialization)(t),
+			),
+			tx.BeginnerWithDriver(
+				txmocks.JoinBeginners(
+					txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollback(nil), nil),
+					txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil),
+				)(t),
+				txmocks.ExpectDriverError(errors.Is, tx.ErrSerialization, tx.ErrSerialization)(t),
+			),
+		}
+
+		called := 0
+
+		err := tx.RunMulti(context.Background(), beginners,
+			func(context.Context) error {
+				called++
+				if called == 1 {
+					return io.ErrUnexpectedEOF
+				}
+
+				return nil
+			},
+			nil,
+			tx.RetrySerialization(1),
+		)
+		if err != nil {
+			t.Fatalf("unexpected error, %+v", err)
+		}
+	})
+
+	t.Run("partial commit never retried", func(t *testing.T) {
+		beginners := []tx.Beginner{
+			txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil)(t),
+			tx.BeginnerWithDriver(
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollbackAfterFailedCommit(io.ErrUnexpectedEOF), nil)(t),
+				txmocks.ExpectDriverError(
+					errors.Is,
+					io.ErrUnexpectedEOF,
+					errors.Join(tx.ErrSerialization, &tx.PartialCommitError{Committed: []int{0}, Failed: 1, Err: io.ErrUnexpectedEOF}),
+				)(t),
+			),
+		}
+
+		err := tx.RunMulti(context.Background(), beginners,
+			func(context.Context) error { return nil },
+			nil,
+			tx.RetrySerialization(3),
+		)
+		if !errors.Is(err, tx.ErrPartialCommit) || !errors.Is(err, tx.ErrSerialization) {
+			t.Fatalf("unexpected error, %+v", err)
+		}
+
+		if errors.Is(err, tx.ErrSerializationRepeatTimesExcedeed) {
+			t.Fatalf("partial commit must not be retried, %+v", err)
+		}
+	})
+}
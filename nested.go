@@ -0,0 +1,29 @@
+package tx
+
+import (
+	"errors"
+	"log/slog"
+)
+
+var ErrNestedBegin = errors.New("nested begin, transaction on the same pool is already held")
+
+// NestedBeginPolicy configures what adapter beginners do when caller already holds
+// a transaction on the pool and asks for another connection, which may exhaust small pools.
+type NestedBeginPolicy int
+
+const (
+	NestedBeginAllow NestedBeginPolicy = iota
+	// NestedBeginFail makes Begin and BeginTx fail with ErrNestedBegin,
+	// Executor can't fail, so it warns.
+	NestedBeginFail
+	// NestedBeginWarn logs a warning with stacks of held transaction and nested acquisition.
+	NestedBeginWarn
+)
+
+// DetectNestedBegin enables nested begin detection, slog.Default() is used if logger is nil.
+func DetectNestedBegin(policy NestedBeginPolicy, logger *slog.Logger) BeginnerOption {
+	return func(o *BeginnerOptions) {
+		o.NestedBegin = policy
+		o.NestedBeginLogger = logger
+	}
+}
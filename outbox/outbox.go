@@ -0,0 +1,51 @@
+// Package outbox implements transactional outbox over the transactions of sql, sqlx and bun adapters.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/amidgo/tx/internal/sqlctx"
+)
+
+var ErrNoTx = errors.New("outbox: publish outside of transaction")
+
+// CreateTableQuery creates outbox table in Postgres database.
+const CreateTableQuery = `
+CREATE TABLE IF NOT EXISTS tx_outbox (
+	id bigserial PRIMARY KEY,
+	topic text NOT NULL,
+	payload bytea NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	delivered_at timestamptz
+)`
+
+type Message struct {
+	ID        int64
+	Topic     string
+	Payload   []byte
+	CreatedAt time.Time
+}
+
+// Publish stores message in outbox table within transaction of txCtx,
+// message is relayed only if the transaction commits.
+// tx.ErrAmbiguousTx is returned if txCtx holds transactions of several databases, e.g. inside tx.RunMulti.
+func Publish(txCtx context.Context, topic string, payload []byte) error {
+	tx, err := sqlctx.Current(txCtx)
+	if errors.Is(err, sqlctx.ErrNoTx) {
+		return ErrNoTx
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Tx.ExecContext(txCtx,
+		"INSERT INTO tx_outbox (topic, payload) VALUES ($1, $2)",
+		topic,
+		payload,
+	)
+
+	return err
+}
@@ -0,0 +1,198 @@
+package outbox_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+
+	postgrescontainer "github.com/amidgo/containers/postgres"
+	"github.com/amidgo/containers/postgres/migrations"
+	"github.com/amidgo/tx"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+
+	"github.com/amidgo/tx/internal/reusable"
+	"github.com/amidgo/tx/outbox"
+
+	buntx "github.com/amidgo/tx/bun"
+	sqltx "github.com/amidgo/tx/sql"
+	sqlxtx "github.com/amidgo/tx/sqlx"
+)
+
+type memoryPublisher struct {
+	mu       sync.Mutex
+	messages []outbox.Message
+	failOn   string
+}
+
+func (m *memoryPublisher) Publish(_ context.Context, msg outbox.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if msg.Topic == m.failOn {
+		return errPublish
+	}
+
+	m.messages = append(m.messages, msg)
+
+	return nil
+}
+
+func (m *memoryPublisher) payloads() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	payloads := make([]string, len(m.messages))
+	for i, msg := range m.messages {
+		payloads[i] = msg.Topic + ":" + string(msg.Payload)
+	}
+
+	return payloads
+}
+
+var errPublish = errors.New("publish failed")
+
+func Test_Publish_NoTx(t *testing.T) {
+	t.Parallel()
+
+	err := outbox.Publish(context.Background(), "topic", []byte("payload"))
+	require.ErrorIs(t, err, outbox.ErrNoTx)
+}
+
+func Test_Outbox(t *testing.T) {
+	t.Parallel()
+
+	db := postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil, outbox.CreateTableQuery)
+
+	beginners := []tx.Beginner{
+		sqltx.NewBeginner(db),
+		sqlxtx.NewBeginner(sqlx.NewDb(db, "pgx")),
+		buntx.NewBeginner(bun.NewDB(db, pgdialect.New())),
+	}
+
+	ctx := context.Background()
+
+	for i, beginner := range beginners {
+		err := tx.Run(ctx, beginner,
+			func(txContext context.Context) error {
+				return outbox.Publish(txContext, "committed", []byte{byte('0' + i)})
+			},
+			nil,
+		)
+		require.NoError(t, err)
+
+		errRollback := errors.New("rollback")
+
+		err = tx.Run(ctx, beginner,
+			func(txContext context.Context) error {
+				err := outbox.Publish(txContext, "rolled_back", []byte{byte('0' + i)})
+				require.NoError(t, err)
+
+				return errRollback
+			},
+			nil,
+		)
+		require.ErrorIs(t, err, errRollback)
+	}
+
+	publisher := &memoryPublisher{}
+	relay := outbox.NewRelay(db, publisher, outbox.WithBatchSize(2))
+
+	relayed, err := relay.RelayBatch(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, relayed)
+
+	relayed, err = relay.RelayBatch(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, relayed)
+
+	relayed, err = relay.RelayBatch(ctx)
+	require.NoError(t, err)
+	require.Zero(t, relayed)
+
+	require.Equal(t, []string{"committed:0", "committed:1", "committed:2"}, publisher.payloads())
+}
+
+func Test_Relay_PublishFailed(t *testing.T) {
+	t.Parallel()
+
+	db := postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil, outbox.CreateTableQuery)
+
+	beginner := sqltx.NewBeginner(db)
+
+	ctx := context.Background()
+
+	err := tx.Run(ctx, beginner,
+		func(txContext context.Context) error {
+			for _, topic := range []string{"first", "broken", "last"} {
+				err := outbox.Publish(txContext, topic, []byte("payload"))
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+		nil,
+	)
+	require.NoError(t, err)
+
+	publisher := &memoryPublisher{failOn: "broken"}
+	relay := outbox.NewRelay(db, publisher)
+
+	relayed, err := relay.RelayBatch(ctx)
+	require.ErrorIs(t, err, errPublish)
+	require.Equal(t, 1, relayed)
+
+	publisher.mu.Lock()
+	publisher.failOn = ""
+	publisher.mu.Unlock()
+
+	relayed, err = relay.RelayBatch(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, relayed)
+
+	require.Equal(t, []string{"first:payload", "broken:payload", "last:payload"}, publisher.payloads())
+}
+
+func Test_Relay_SkipLocked(t *testing.T) {
+	t.Parallel()
+
+	db := postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil, outbox.CreateTableQuery)
+
+	ctx := context.Background()
+
+	err := tx.Run(ctx, sqltx.NewBeginner(db),
+		func(txContext context.Context) error {
+			return outbox.Publish(txContext, "locked", []byte("payload"))
+		},
+		nil,
+	)
+	require.NoError(t, err)
+
+	lockTx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	require.NoError(t, err)
+
+	defer lockTx.Rollback()
+
+	_, err = lockTx.ExecContext(ctx, "SELECT id FROM tx_outbox FOR UPDATE")
+	require.NoError(t, err)
+
+	publisher := &memoryPublisher{}
+	relay := outbox.NewRelay(db, publisher)
+
+	relayed, err := relay.RelayBatch(ctx)
+	require.NoError(t, err)
+	require.Zero(t, relayed)
+
+	err = lockTx.Rollback()
+	require.NoError(t, err)
+
+	relayed, err = relay.RelayBatch(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, relayed)
+}
@@ -0,0 +1,170 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/amidgo/tx"
+	sqltx "github.com/amidgo/tx/sql"
+)
+
+// Publisher delivers outbox messages to a broker.
+type Publisher interface {
+	Publish(ctx context.Context, msg Message) error
+}
+
+const (
+	defaultBatchSize    = 100
+	defaultPollInterval = time.Second
+)
+
+type RelayOption func(*Relay)
+
+// WithBatchSize sets max count of messages relayed in one transaction, 100 by default.
+func WithBatchSize(size int) RelayOption {
+	return func(r *Relay) {
+		r.batchSize = size
+	}
+}
+
+// WithPollInterval sets pause of Run after relayed batch that was not full, 1 second by default.
+func WithPollInterval(interval time.Duration) RelayOption {
+	return func(r *Relay) {
+		r.pollInterval = interval
+	}
+}
+
+// WithLogger sets logger of Run errors, slog.Default by default.
+func WithLogger(logger *slog.Logger) RelayOption {
+	return func(r *Relay) {
+		r.logger = logger
+	}
+}
+
+// Relay hands undelivered messages of outbox table to Publisher in order of publishing.
+// Messages locked by concurrent relays are skipped, so relays may run on several instances.
+type Relay struct {
+	beginner  *sqltx.Beginner
+	publisher Publisher
+
+	batchSize    int
+	pollInterval time.Duration
+	logger       *slog.Logger
+}
+
+func NewRelay(db *sql.DB, publisher Publisher, opts ...RelayOption) *Relay {
+	r := &Relay{
+		beginner:     sqltx.NewBeginner(db),
+		publisher:    publisher,
+		batchSize:    defaultBatchSize,
+		pollInterval: defaultPollInterval,
+		logger:       slog.Default(),
+	}
+
+	for _, op := range opts {
+		op(r)
+	}
+
+	return r
+}
+
+// Run relays messages until ctx is done, errors are logged and relaying continues after poll interval.
+func (r *Relay) Run(ctx context.Context) error {
+	for {
+		relayed, err := r.RelayBatch(ctx)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "outbox relay failed", slog.Any("error", err))
+		}
+
+		if err == nil && relayed == r.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.pollInterval):
+		}
+	}
+}
+
+// RelayBatch relays one batch of messages and returns count of delivered ones.
+// Messages delivered before Publisher failure are marked delivered, the rest are retried by the next batch.
+func (r *Relay) RelayBatch(ctx context.Context) (int, error) {
+	var (
+		relayed    int
+		publishErr error
+	)
+
+	err := tx.Run(ctx, r.beginner,
+		func(txContext context.Context) error {
+			relayed, publishErr = 0, nil
+
+			messages, err := r.lockBatch(txContext)
+			if err != nil {
+				return err
+			}
+
+			exec := r.beginner.Executor(txContext)
+
+			for _, msg := range messages {
+				err = r.publisher.Publish(txContext, msg)
+				if err != nil {
+					publishErr = fmt.Errorf("publish message %d, %w", msg.ID, err)
+
+					break
+				}
+
+				_, err = exec.ExecContext(txContext, "UPDATE tx_outbox SET delivered_at = now() WHERE id = $1", msg.ID)
+				if err != nil {
+					return err
+				}
+
+				relayed++
+			}
+
+			// delivered messages are committed despite publish failure
+			return nil
+		},
+		nil,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return relayed, publishErr
+}
+
+func (r *Relay) lockBatch(txContext context.Context) ([]Message, error) {
+	rows, err := r.beginner.Executor(txContext).QueryContext(txContext,
+		`SELECT id, topic, payload, created_at FROM tx_outbox
+		WHERE delivered_at IS NULL
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`,
+		r.batchSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	messages := make([]Message, 0, r.batchSize)
+
+	for rows.Next() {
+		var msg Message
+
+		err := rows.Scan(&msg.ID, &msg.Topic, &msg.Payload, &msg.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, msg)
+	}
+
+	return messages, rows.Err()
+}
@@ -1,7 +1,10 @@
 package pgxtx
 
 import (
+	sqldriver "database/sql/driver"
 	"errors"
+	"net"
+	"strings"
 
 	"github.com/amidgo/tx"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -20,8 +23,50 @@ func (driver) Error(err error) error {
 		switch pgErr.Code {
 		case "40P01", "40001":
 			err = errors.Join(tx.ErrSerialization, err)
+		case "23505":
+			err = errors.Join(tx.ErrUniqueViolation, err)
+		case "57P01", "57P02", "57P03":
+			err = errors.Join(tx.ErrConnection, err)
+		default:
+			// connection exception class
+			if strings.HasPrefix(pgErr.Code, "08") {
+				err = errors.Join(tx.ErrConnection, err)
+			}
 		}
+
+		return err
+	}
+
+	if connectionFailed(err) {
+		err = errors.Join(tx.ErrConnection, err)
 	}
 
 	return err
 }
+
+// CommitError reports commit error as *tx.CommitUnknownError unless the server answered with an error
+// or COMMIT was never sent, timeouts and ctx cancellation during COMMIT leave the outcome unknown as well.
+// database/sql reports errors before sending as driver.ErrBadConn.
+func (d driver) CommitError(err error) error {
+	err = d.Error(err)
+
+	var pgErr *pgconn.PgError
+
+	if errors.As(err, &pgErr) || errors.Is(err, sqldriver.ErrBadConn) || pgconn.SafeToRetry(err) {
+		return err
+	}
+
+	return &tx.CommitUnknownError{Err: err}
+}
+
+func connectionFailed(err error) bool {
+	var connectErr *pgconn.ConnectError
+
+	if errors.As(err, &connectErr) || errors.Is(err, sqldriver.ErrBadConn) || pgconn.SafeToRetry(err) {
+		return true
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr) && !netErr.Timeout()
+}
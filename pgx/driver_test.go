@@ -3,7 +3,11 @@ package pgxtx_test
 import (
 	"context"
 	"database/sql"
+	sqldriver "database/sql/driver"
 	"errors"
+	"net"
+	"os"
+	"syscall"
 	"testing"
 
 	postgrescontainer "github.com/amidgo/containers/postgres"
@@ -11,6 +15,7 @@ import (
 	"github.com/amidgo/tx"
 	"github.com/amidgo/tx/internal/reusable"
 	pgxtx "github.com/amidgo/tx/pgx"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 func Test_Driver(t *testing.T) {
@@ -18,6 +23,7 @@ func Test_Driver(t *testing.T) {
 
 	t.Run("serializable level", driverSerializationTest)
 	t.Run("repeatable read", driverRepeatableReadTest)
+	t.Run("unique violation", driverUniqueViolationTest)
 }
 
 func driverSerializationTest(t *testing.T) {
@@ -138,3 +144,86 @@ CREATE TABLE accounts (
 		t.Fatalf("expected serialization error, actual %+v", err)
 	}
 }
+
+func driverUniqueViolationTest(t *testing.T) {
+	t.Parallel()
+
+	db := postgrescontainer.ReuseForTesting(t,
+		reusable.Postgres(),
+		migrations.Nil,
+		"create table tx_unique_keys(key text primary key)",
+		"insert into tx_unique_keys(key) values ('key')",
+	)
+
+	_, err := db.Exec("insert into tx_unique_keys(key) values ('key')")
+
+	driverErr := pgxtx.Driver().Error(err)
+
+	if !errors.Is(driverErr, err) {
+		t.Fatalf("invalid error wrapping, original error was erased, original: %+v, driverErr: %+v", err, driverErr)
+	}
+
+	if !errors.Is(driverErr, tx.ErrUniqueViolation) {
+		t.Fatalf("expected unique violation error, actual %+v", err)
+	}
+
+	if errors.Is(driverErr, tx.ErrSerialization) {
+		t.Fatalf("unexpected serialization error, actual %+v", err)
+	}
+}
+
+func Test_Driver_Connection(t *testing.T) {
+	t.Parallel()
+
+	connectionErrors := []error{
+		&pgconn.PgError{Code: "57P01"},
+		&pgconn.PgError{Code: "08006"},
+		sqldriver.ErrBadConn,
+		&net.OpError{Op: "read", Err: syscall.ECONNRESET},
+	}
+
+	for _, err := range connectionErrors {
+		driverErr := pgxtx.Driver().Error(err)
+
+		if !errors.Is(driverErr, err) {
+			t.Fatalf("invalid error wrapping, original error was erased, original: %+v, driverErr: %+v", err, driverErr)
+		}
+
+		if !errors.Is(driverErr, tx.ErrConnection) {
+			t.Fatalf("expected connection error, actual %+v", driverErr)
+		}
+	}
+
+	driverErr := pgxtx.Driver().Error(&pgconn.PgError{Code: "23503"})
+	if errors.Is(driverErr, tx.ErrConnection) {
+		t.Fatalf("unexpected connection error, actual %+v", driverErr)
+	}
+}
+
+func Test_Driver_CommitError(t *testing.T) {
+	t.Parallel()
+
+	commitDriver, ok := pgxtx.Driver().(tx.CommitDriver)
+	if !ok {
+		t.Fatal("pgx driver expected to classify commit errors")
+	}
+
+	for _, err := range []error{
+		&net.OpError{Op: "read", Err: syscall.ECONNRESET},
+		&net.OpError{Op: "read", Err: os.ErrDeadlineExceeded},
+		context.DeadlineExceeded,
+		context.Canceled,
+	} {
+		commitErr := commitDriver.CommitError(err)
+		if !errors.Is(commitErr, tx.ErrCommitUnknown) || !errors.Is(commitErr, err) {
+			t.Fatalf("expected unknown commit error, actual %+v", commitErr)
+		}
+	}
+
+	for _, err := range []error{sqldriver.ErrBadConn, &pgconn.PgError{Code: "40001"}} {
+		commitErr := commitDriver.CommitError(err)
+		if errors.Is(commitErr, tx.ErrCommitUnknown) {
+			t.Fatalf("unexpected unknown commit error, actual %+v", commitErr)
+		}
+	}
+}
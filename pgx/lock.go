@@ -0,0 +1,59 @@
+package pgxtx
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+
+	"github.com/amidgo/tx/internal/sqlctx"
+)
+
+// ErrLockOutsideTx is returned by transaction scoped locks called without transaction,
+// such lock would be released immediately.
+var ErrLockOutsideTx = errors.New("advisory xact lock outside of transaction")
+
+// AdvisoryKey hashes key to int64 key of Postgres advisory locks by FNV-1a.
+func AdvisoryKey(key string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+
+	return int64(h.Sum64())
+}
+
+// LockXact waits for pg_advisory_xact_lock of key in transaction of txCtx,
+// the lock is released when the transaction ends.
+// tx.ErrAmbiguousTx is returned if txCtx holds transactions of several databases.
+func LockXact(txCtx context.Context, key string) error {
+	tx, err := sqlctx.Current(txCtx)
+	if errors.Is(err, sqlctx.ErrNoTx) {
+		return ErrLockOutsideTx
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Tx.ExecContext(txCtx, "SELECT pg_advisory_xact_lock($1)", AdvisoryKey(key))
+
+	return err
+}
+
+// TryLockXact takes pg_try_advisory_xact_lock of key in transaction of txCtx without waiting,
+// reports false if the lock is held by another transaction.
+// tx.ErrAmbiguousTx is returned if txCtx holds transactions of several databases.
+func TryLockXact(txCtx context.Context, key string) (bool, error) {
+	tx, err := sqlctx.Current(txCtx)
+	if errors.Is(err, sqlctx.ErrNoTx) {
+		return false, ErrLockOutsideTx
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	var locked bool
+
+	err = tx.Tx.QueryRowContext(txCtx, "SELECT pg_try_advisory_xact_lock($1)", AdvisoryKey(key)).Scan(&locked)
+
+	return locked, err
+}
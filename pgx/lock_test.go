@@ -0,0 +1,92 @@
+package pgxtx_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	postgrescontainer "github.com/amidgo/containers/postgres"
+	"github.com/amidgo/containers/postgres/migrations"
+	"github.com/amidgo/tx/internal/reusable"
+	pgxtx "github.com/amidgo/tx/pgx"
+	sqltx "github.com/amidgo/tx/sql"
+)
+
+func Test_AdvisoryKey(t *testing.T) {
+	t.Parallel()
+
+	if pgxtx.AdvisoryKey("tenant-1") != pgxtx.AdvisoryKey("tenant-1") {
+		t.Fatal("key hash must be stable")
+	}
+
+	if pgxtx.AdvisoryKey("tenant-1") == pgxtx.AdvisoryKey("tenant-2") {
+		t.Fatal("different keys expected to have different hashes")
+	}
+}
+
+func Test_LockXact_OutsideTx(t *testing.T) {
+	t.Parallel()
+
+	err := pgxtx.LockXact(context.Background(), "tenant")
+	if !errors.Is(err, pgxtx.ErrLockOutsideTx) {
+		t.Fatalf("unexpected error, expected %s, actual %v", pgxtx.ErrLockOutsideTx, err)
+	}
+
+	_, err = pgxtx.TryLockXact(context.Background(), "tenant")
+	if !errors.Is(err, pgxtx.ErrLockOutsideTx) {
+		t.Fatalf("unexpected error, expected %s, actual %v", pgxtx.ErrLockOutsideTx, err)
+	}
+}
+
+func Test_LockXact(t *testing.T) {
+	t.Parallel()
+
+	db := postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil)
+
+	beginner := sqltx.NewBeginner(db)
+
+	ctx := context.Background()
+
+	holder, err := beginner.Begin(ctx)
+	if err != nil {
+		t.Fatalf("begin holder tx, %s", err)
+	}
+
+	defer holder.Rollback()
+
+	err = pgxtx.LockXact(holder.Context(), "tenant")
+	if err != nil {
+		t.Fatalf("lock xact, %s", err)
+	}
+
+	contender, err := beginner.Begin(ctx)
+	if err != nil {
+		t.Fatalf("begin contender tx, %s", err)
+	}
+
+	defer contender.Rollback()
+
+	locked, err := pgxtx.TryLockXact(contender.Context(), "tenant")
+	if err != nil {
+		t.Fatalf("try lock xact, %s", err)
+	}
+
+	if locked {
+		t.Fatal("lock held by another transaction must not be taken")
+	}
+
+	locked, err = pgxtx.TryLockXact(contender.Context(), "other-tenant")
+	if err != nil || !locked {
+		t.Fatalf("lock of another key expected, locked %t, err %v", locked, err)
+	}
+
+	err = holder.Commit()
+	if err != nil {
+		t.Fatalf("commit holder tx, %s", err)
+	}
+
+	locked, err = pgxtx.TryLockXact(contender.Context(), "tenant")
+	if err != nil || !locked {
+		t.Fatalf("lock released on commit expected, locked %t, err %v", locked, err)
+	}
+}
@@ -0,0 +1,199 @@
+package pgxtx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/amidgo/tx"
+)
+
+var (
+	ErrInvalidLSN = errors.New("invalid lsn")
+	ErrNotReplica = errors.New("database is not a replica")
+)
+
+// LSN is a Postgres write-ahead log position.
+type LSN uint64
+
+// ParseLSN parses LSN in Postgres text form, e.g. "16/B374D848".
+func ParseLSN(s string) (LSN, error) {
+	hi, lo, ok := strings.Cut(s, "/")
+	if !ok {
+		return 0, fmt.Errorf("%w, %q", ErrInvalidLSN, s)
+	}
+
+	h, err := strconv.ParseUint(hi, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%w, %q", ErrInvalidLSN, s)
+	}
+
+	l, err := strconv.ParseUint(lo, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%w, %q", ErrInvalidLSN, s)
+	}
+
+	return LSN(h<<32 | l), nil
+}
+
+func (l LSN) String() string {
+	return fmt.Sprintf("%X/%X", uint64(l)>>32, uint64(l)&0xFFFFFFFF)
+}
+
+// Session tracks the LSN of the latest primary commit made by the session,
+// safe for concurrent use.
+type Session struct {
+	lsn atomic.Uint64
+}
+
+// ParseSession restores session from token returned by Session.Token, empty token gives empty session.
+func ParseSession(token string) (*Session, error) {
+	session := &Session{}
+
+	if token == "" {
+		return session, nil
+	}
+
+	lsn, err := ParseLSN(token)
+	if err != nil {
+		return nil, err
+	}
+
+	session.Advance(lsn)
+
+	return session, nil
+}
+
+// Token returns session token to be stored between requests, e.g. in a cookie.
+func (s *Session) Token() string {
+	lsn := s.LSN()
+	if lsn == 0 {
+		return ""
+	}
+
+	return lsn.String()
+}
+
+func (s *Session) LSN() LSN {
+	return LSN(s.lsn.Load())
+}
+
+// Advance moves session LSN forward, smaller lsn is ignored.
+func (s *Session) Advance(lsn LSN) {
+	for {
+		current := s.lsn.Load()
+		if uint64(lsn) <= current {
+			return
+		}
+
+		if s.lsn.CompareAndSwap(current, uint64(lsn)) {
+			return
+		}
+	}
+}
+
+type sessionKey struct{}
+
+func ContextWithSession(ctx context.Context, session *Session) context.Context {
+	return context.WithValue(ctx, sessionKey{}, session)
+}
+
+func SessionFromContext(ctx context.Context) (*Session, bool) {
+	session, ok := ctx.Value(sessionKey{}).(*Session)
+
+	return session, ok
+}
+
+// CurrentLSNFunc returns current primary LSN.
+type CurrentLSNFunc func(ctx context.Context) (LSN, error)
+
+// ReplayLSNFunc returns LSN replayed by replica.
+type ReplayLSNFunc func(ctx context.Context, replica tx.Beginner) (LSN, error)
+
+// ReadYourWrites makes RoutingBeginner read own writes of the session placed into ctx by ContextWithSession.
+//
+// After every primary commit session is advanced to currentLSN,
+// read only work is routed only to replicas with replayLSN caught up with the session,
+// to primary if there is no such replica.
+// Failed currentLSN leaves session unchanged, failed replayLSN excludes the replica.
+// Replica filters and primary commit hooks of other options are kept along with ones of ReadYourWrites.
+func ReadYourWrites(currentLSN CurrentLSNFunc, replayLSN ReplayLSNFunc) tx.RoutingOption {
+	onCommit := tx.OnPrimaryCommit(
+		func(ctx context.Context) {
+			session, ok := SessionFromContext(ctx)
+			if !ok {
+				return
+			}
+
+			lsn, err := currentLSN(context.WithoutCancel(ctx))
+			if err != nil {
+				return
+			}
+
+			session.Advance(lsn)
+		},
+	)
+
+	filter := tx.WithReplicaFilter(
+		func(ctx context.Context, replica tx.Beginner) bool {
+			session, ok := SessionFromContext(ctx)
+			if !ok || session.LSN() == 0 {
+				return true
+			}
+
+			lsn, err := replayLSN(ctx, replica)
+			if err != nil {
+				return false
+			}
+
+			return lsn >= session.LSN()
+		},
+	)
+
+	return func(r *tx.RoutingBeginner) {
+		onCommit(r)
+		filter(r)
+	}
+}
+
+// CurrentLSN reads pg_current_wal_lsn() of primary db.
+func CurrentLSN(db *sql.DB) CurrentLSNFunc {
+	return func(ctx context.Context) (LSN, error) {
+		var lsn string
+
+		err := db.QueryRowContext(ctx, "SELECT pg_current_wal_lsn()::text").Scan(&lsn)
+		if err != nil {
+			return 0, err
+		}
+
+		return ParseLSN(lsn)
+	}
+}
+
+// ReplayLSN reads pg_last_wal_replay_lsn() of replica db,
+// replicas holds db of every replica beginner passed to RoutingBeginner.
+func ReplayLSN(replicas map[tx.Beginner]*sql.DB) ReplayLSNFunc {
+	return func(ctx context.Context, replica tx.Beginner) (LSN, error) {
+		db, ok := replicas[replica]
+		if !ok {
+			return 0, ErrNotReplica
+		}
+
+		var lsn sql.NullString
+
+		err := db.QueryRowContext(ctx, "SELECT pg_last_wal_replay_lsn()::text").Scan(&lsn)
+		if err != nil {
+			return 0, err
+		}
+
+		if !lsn.Valid {
+			return 0, ErrNotReplica
+		}
+
+		return ParseLSN(lsn.String)
+	}
+}
@@ -0,0 +1,147 @@
+package pgxtx_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	postgrescontainer "github.com/amidgo/containers/postgres"
+	"github.com/amidgo/containers/postgres/migrations"
+	"github.com/amidgo/tx"
+	"github.com/amidgo/tx/internal/reusable"
+	txmocks "github.com/amidgo/tx/mocks"
+	pgxtx "github.com/amidgo/tx/pgx"
+)
+
+func Test_ParseLSN(t *testing.T) {
+	t.Parallel()
+
+	lsn, err := pgxtx.ParseLSN("16/B374D848")
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+
+	if lsn != 0x16_B374D848 {
+		t.Fatalf("unexpected lsn, %d", lsn)
+	}
+
+	if lsn.String() != "16/B374D848" {
+		t.Fatalf("unexpected lsn string, %s", lsn)
+	}
+
+	for _, invalid := range []string{"", "16", "16/", "X/1", "1/100000000"} {
+		_, err := pgxtx.ParseLSN(invalid)
+		if !errors.Is(err, pgxtx.ErrInvalidLSN) {
+			t.Fatalf("expected ErrInvalidLSN for %q, actual %v", invalid, err)
+		}
+	}
+}
+
+func Test_Session(t *testing.T) {
+	t.Parallel()
+
+	session, err := pgxtx.ParseSession("")
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+
+	if session.Token() != "" {
+		t.Fatalf("empty token expected, actual %s", session.Token())
+	}
+
+	session.Advance(20)
+	session.Advance(10)
+
+	if session.LSN() != 20 {
+		t.Fatalf("session must not move backward, lsn %d", session.LSN())
+	}
+
+	restored, err := pgxtx.ParseSession(session.Token())
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+
+	if restored.LSN() != 20 {
+		t.Fatalf("unexpected restored lsn, %d", restored.LSN())
+	}
+}
+
+func Test_ReadYourWrites(t *testing.T) {
+	t.Parallel()
+
+	readOnly := &sql.TxOptions{ReadOnly: true}
+
+	lagging := txmocks.ExpectNothing()(t)
+	caughtUp := txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, readOnly)(t)
+
+	replayed := map[tx.Beginner]pgxtx.LSN{
+		lagging:  100,
+		caughtUp: 200,
+	}
+
+	beginner := tx.NewRoutingBeginner(
+		txmocks.JoinBeginners(
+			txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil),
+			txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, readOnly),
+		)(t),
+		[]tx.Beginner{lagging, caughtUp},
+		pgxtx.ReadYourWrites(
+			func(context.Context) (pgxtx.LSN, error) { return 150, nil },
+			func(_ context.Context, replica tx.Beginner) (pgxtx.LSN, error) {
+				return replayed[replica], nil
+			},
+		),
+	)
+
+	session := &pgxtx.Session{}
+	ctx := pgxtx.ContextWithSession(context.Background(), session)
+
+	run := func(opts *sql.TxOptions) {
+		err := tx.Run(ctx, beginner, func(context.Context) error { return nil }, opts)
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+	}
+
+	run(nil)
+
+	if session.LSN() != 150 {
+		t.Fatalf("session must be advanced to commit lsn, actual %d", session.LSN())
+	}
+
+	// goes to caught up replica only
+	run(readOnly)
+
+	// no replica caught up, primary expected
+	session.Advance(300)
+	run(readOnly)
+
+	if beginner.Route(tx.ReadOnlyContext(ctx)) == lagging {
+		t.Fatal("lagging replica must not be routed")
+	}
+}
+
+func Test_LSN_Postgres(t *testing.T) {
+	t.Parallel()
+
+	db := postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil)
+
+	ctx := context.Background()
+
+	lsn, err := pgxtx.CurrentLSN(db)(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+
+	if lsn == 0 {
+		t.Fatal("non zero lsn expected")
+	}
+
+	primary := txmocks.ExpectNothing()(t)
+
+	_, err = pgxtx.ReplayLSN(map[tx.Beginner]*sql.DB{primary: db})(ctx, primary)
+	if !errors.Is(err, pgxtx.ErrNotReplica) {
+		t.Fatalf("expected ErrNotReplica, actual %v", err)
+	}
+}
@@ -0,0 +1,340 @@
+package pgxtx
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/amidgo/tx"
+	"github.com/amidgo/tx/internal/sqlctx"
+	"github.com/amidgo/tx/internal/txstate"
+)
+
+var (
+	// ErrCommitIncomplete is returned when commit was decided but not every participant committed,
+	// remaining participants are committed by Coordinator.Recover.
+	ErrCommitIncomplete = errors.New("two-phase commit decided but not completed, recovery required")
+	ErrNoParticipantTx  = errors.New("participant transaction not found in context")
+)
+
+// CreateCoordinatorLogQuery creates table of commit decisions in the coordinator log database.
+const CreateCoordinatorLogQuery = `
+CREATE TABLE IF NOT EXISTS tx_coordinator_log (
+	id text PRIMARY KEY,
+	created_at timestamptz NOT NULL DEFAULT now()
+)`
+
+const defaultRecoveryAge = 5 * time.Minute
+
+// Participant of two-phase commit, Beginner must be an adapter beginner over DB.
+type Participant struct {
+	Beginner tx.Beginner
+	DB       *sql.DB
+}
+
+type CoordinatorOption func(*Coordinator)
+
+// WithRecoveryAge sets age of prepared transactions resolved by Recover, 5 minutes by default.
+// It must exceed the longest commit, younger prepared transactions may still be in progress.
+func WithRecoveryAge(age time.Duration) CoordinatorOption {
+	return func(c *Coordinator) {
+		c.recoveryAge = age
+	}
+}
+
+var _ tx.Beginner = (*Coordinator)(nil)
+
+// Coordinator begins transaction on every participant and commits them atomically with Postgres two-phase commit,
+// participants databases must have max_prepared_transactions enabled.
+//
+// Commit prepares participant i as "<name>:<id>:<i>", persists decision <name>:<id> in log database
+// and runs COMMIT PREPARED on every participant.
+// Failed prepare or decision rolls back every participant.
+type Coordinator struct {
+	name         string
+	log          *sql.DB
+	participants []Participant
+	recoveryAge  time.Duration
+}
+
+// NewCoordinator creates coordinator, name must be unique among coordinators sharing participants databases.
+func NewCoordinator(name string, log *sql.DB, participants []Participant, opts ...CoordinatorOption) *Coordinator {
+	c := &Coordinator{
+		name:         name,
+		log:          log,
+		participants: participants,
+		recoveryAge:  defaultRecoveryAge,
+	}
+
+	for _, op := range opts {
+		op(c)
+	}
+
+	return c
+}
+
+func (c *Coordinator) Begin(ctx context.Context) (tx.Tx, error) {
+	return c.BeginTx(ctx, nil)
+}
+
+func (c *Coordinator) BeginTx(ctx context.Context, opts *sql.TxOptions) (tx.Tx, error) {
+	id, err := newTransactionID()
+	if err != nil {
+		return nil, err
+	}
+
+	t := &twoPhaseTx{
+		coordinator: c,
+		ctx:         ctx,
+		id:          c.name + ":" + id,
+		txs:         make([]tx.Tx, 0, len(c.participants)),
+	}
+
+	for i, participant := range c.participants {
+		participantTx, err := participant.Beginner.BeginTx(t.ctx, opts)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("begin on participant %d, %w", i, err), t.rollback())
+		}
+
+		t.txs = append(t.txs, participantTx)
+		t.ctx = participantTx.Context()
+	}
+
+	return t, nil
+}
+
+func (c *Coordinator) Driver() tx.Driver {
+	return Driver()
+}
+
+// Recover resolves prepared transactions of the coordinator older than recovery age left after a crash,
+// commits the ones with persisted decision and rolls back the rest.
+// Decisions of the resolved transactions are removed from the log.
+// Transactions are matched by exact shape of the coordinator gid, so coordinator "a" leaves ones of "a:b" alone.
+func (c *Coordinator) Recover(ctx context.Context) error {
+	errs := make([]error, 0)
+	visited := make(map[*sql.DB]bool, len(c.participants))
+
+	for _, participant := range c.participants {
+		if visited[participant.DB] {
+			continue
+		}
+
+		visited[participant.DB] = true
+
+		gids, err := c.inDoubt(ctx, participant.DB)
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		for _, gid := range gids {
+			err := c.resolve(ctx, participant.DB, gid)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("resolve %s, %w", gid, err))
+			}
+		}
+	}
+
+	if len(errs) != 0 {
+		return errors.Join(errs...)
+	}
+
+	// decisions are persisted after prepare, so old decisions have no prepared transactions left
+	_, err := c.log.ExecContext(ctx,
+		`DELETE FROM tx_coordinator_log
+		WHERE left(id, length($1)) = $1 AND substr(id, length($1) + 1) ~ '^[0-9a-f]{32}$'
+			AND created_at < now() - make_interval(secs => $2)`,
+		c.name+":",
+		c.recoveryAge.Seconds(),
+	)
+
+	return err
+}
+
+func (c *Coordinator) inDoubt(ctx context.Context, db *sql.DB) ([]string, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT gid FROM pg_prepared_xacts
+		WHERE database = current_database()
+			AND left(gid, length($1)) = $1 AND substr(gid, length($1) + 1) ~ '^[0-9a-f]{32}:[0-9]+$'
+			AND prepared < now() - make_interval(secs => $2)`,
+		c.name+":",
+		c.recoveryAge.Seconds(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	gids := make([]string, 0)
+
+	for rows.Next() {
+		var gid string
+
+		err := rows.Scan(&gid)
+		if err != nil {
+			return nil, err
+		}
+
+		gids = append(gids, gid)
+	}
+
+	return gids, rows.Err()
+}
+
+func (c *Coordinator) resolve(ctx context.Context, db *sql.DB, gid string) error {
+	id := gid[:strings.LastIndex(gid, ":")]
+
+	var decided bool
+
+	err := c.log.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM tx_coordinator_log WHERE id = $1)",
+		id,
+	).Scan(&decided)
+	if err != nil {
+		return err
+	}
+
+	if decided {
+		_, err = db.ExecContext(ctx, "COMMIT PREPARED "+quoteLiteral(gid))
+	} else {
+		_, err = db.ExecContext(ctx, "ROLLBACK PREPARED "+quoteLiteral(gid))
+	}
+
+	return err
+}
+
+type twoPhaseTx struct {
+	coordinator *Coordinator
+	ctx         context.Context
+	id          string
+	txs         []tx.Tx
+
+	// prepared is count of prepared participants, they are resolved by gid
+	prepared int
+	resolved bool
+	state    txstate.Machine
+}
+
+func (t *twoPhaseTx) Context() context.Context {
+	return t.ctx
+}
+
+func (t *twoPhaseTx) Commit() error {
+	return t.state.Commit(t.commit)
+}
+
+func (t *twoPhaseTx) Rollback() error {
+	return t.state.Rollback(t.rollback)
+}
+
+func (t *twoPhaseTx) State() tx.TxState {
+	return tx.TxState(t.state.State())
+}
+
+func (t *twoPhaseTx) commit() error {
+	for i := range t.txs {
+		err := t.prepare(i)
+		if err != nil {
+			return errors.Join(fmt.Errorf("prepare participant %d, %w", i, err), t.rollback())
+		}
+	}
+
+	// commit must be completed once decided
+	ctx := context.WithoutCancel(t.ctx)
+
+	_, err := t.coordinator.log.ExecContext(ctx, "INSERT INTO tx_coordinator_log (id) VALUES ($1)", t.id)
+	if err != nil {
+		return errors.Join(fmt.Errorf("persist decision, %w", err), t.rollback())
+	}
+
+	t.resolved = true
+
+	errs := make([]error, 0)
+
+	for i, participant := range t.coordinator.participants {
+		_, err := participant.DB.ExecContext(ctx, "COMMIT PREPARED "+quoteLiteral(t.gid(i)))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("commit prepared participant %d, %w", i, err))
+		}
+	}
+
+	if len(errs) != 0 {
+		return errors.Join(ErrCommitIncomplete, errors.Join(errs...))
+	}
+
+	// leftover decision is removed by Recover
+	_, _ = t.coordinator.log.ExecContext(ctx, "DELETE FROM tx_coordinator_log WHERE id = $1", t.id)
+
+	return nil
+}
+
+func (t *twoPhaseTx) prepare(i int) error {
+	participantTx, ok := sqlctx.FromContext(t.txs[i].Context(), t.coordinator.participants[i].DB)
+	if !ok {
+		return ErrNoParticipantTx
+	}
+
+	_, err := participantTx.Tx.ExecContext(t.ctx, "PREPARE TRANSACTION "+quoteLiteral(t.gid(i)))
+	if err != nil {
+		return err
+	}
+
+	t.prepared++
+
+	// session has no transaction after prepare, commit only releases the connection
+	return t.txs[i].Commit()
+}
+
+func (t *twoPhaseTx) rollback() error {
+	if t.resolved {
+		return nil
+	}
+
+	t.resolved = true
+
+	ctx := context.WithoutCancel(t.ctx)
+	errs := make([]error, 0)
+
+	for i := range t.prepared {
+		_, err := t.coordinator.participants[i].DB.ExecContext(ctx, "ROLLBACK PREPARED "+quoteLiteral(t.gid(i)))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rollback prepared participant %d, %w", i, err))
+		}
+	}
+
+	for i := t.prepared; i < len(t.txs); i++ {
+		err := t.txs[i].Rollback()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rollback participant %d, %w", i, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (t *twoPhaseTx) gid(participant int) string {
+	return fmt.Sprintf("%s:%d", t.id, participant)
+}
+
+func newTransactionID() (string, error) {
+	id := make([]byte, 16)
+
+	_, err := rand.Read(id)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(id), nil
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
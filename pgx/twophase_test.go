@@ -0,0 +1,254 @@
+package pgxtx_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	postgrescontainer "github.com/amidgo/containers/postgres"
+	"github.com/amidgo/containers/postgres/migrations"
+	"github.com/amidgo/tx"
+	"github.com/amidgo/tx/internal/reusable"
+	pgxtx "github.com/amidgo/tx/pgx"
+	sqltx "github.com/amidgo/tx/sql"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	txtest "github.com/amidgo/tx/internal/testing"
+)
+
+const createUsersTableQuery = `
+	CREATE TABLE users (
+		id uuid primary key,
+		age smallint not null
+	)
+`
+
+func twoPhaseDatabases(t *testing.T) (first, second *sql.DB) {
+	first = postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil,
+		createUsersTableQuery,
+		pgxtx.CreateCoordinatorLogQuery,
+	)
+	second = postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil, createUsersTableQuery)
+
+	var maxPrepared int
+
+	err := first.QueryRow("SELECT current_setting('max_prepared_transactions')::int").Scan(&maxPrepared)
+	require.NoError(t, err)
+
+	if maxPrepared == 0 {
+		t.Skip("max_prepared_transactions is disabled")
+	}
+
+	return first, second
+}
+
+func requireNoPrepared(t *testing.T, db *sql.DB) {
+	var count int
+
+	err := db.QueryRow("SELECT count(*) FROM pg_prepared_xacts WHERE database = current_database()").Scan(&count)
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
+
+func Test_Coordinator(t *testing.T) {
+	t.Parallel()
+
+	first, second := twoPhaseDatabases(t)
+
+	coordinator := pgxtx.NewCoordinator("test", first,
+		[]pgxtx.Participant{
+			{Beginner: sqltx.NewBeginner(first), DB: first},
+			{Beginner: sqltx.NewBeginner(second), DB: second},
+		},
+	)
+
+	firstBeginner := sqltx.NewBeginner(first)
+	secondBeginner := sqltx.NewBeginner(second)
+
+	insertUsers := func(txContext context.Context, userID uuid.UUID) error {
+		_, err := firstBeginner.Executor(txContext).ExecContext(txContext, "INSERT INTO users (id, age) VALUES ($1, 1)", userID)
+		if err != nil {
+			return err
+		}
+
+		_, err = secondBeginner.Executor(txContext).ExecContext(txContext, "INSERT INTO users (id, age) VALUES ($1, 2)", userID)
+
+		return err
+	}
+
+	ctx := context.Background()
+
+	committedID := uuid.New()
+
+	err := tx.Run(ctx, coordinator,
+		func(txContext context.Context) error {
+			return insertUsers(txContext, committedID)
+		},
+		nil,
+	)
+	require.NoError(t, err)
+
+	txtest.AssertUserExists(t, first, committedID, 1)
+	txtest.AssertUserExists(t, second, committedID, 2)
+
+	errStub := errors.New("stub")
+	rolledBackID := uuid.New()
+
+	err = tx.Run(ctx, coordinator,
+		func(txContext context.Context) error {
+			err := insertUsers(txContext, rolledBackID)
+			require.NoError(t, err)
+
+			return errStub
+		},
+		nil,
+	)
+	require.ErrorIs(t, err, errStub)
+
+	txtest.AssertUserNotFound(t, first, rolledBackID)
+	txtest.AssertUserNotFound(t, second, rolledBackID)
+
+	// duplicate key fails the body, every participant is rolled back
+	err = tx.Run(ctx, coordinator,
+		func(txContext context.Context) error {
+			return insertUsers(txContext, committedID)
+		},
+		nil,
+	)
+	require.Error(t, err)
+
+	requireNoPrepared(t, first)
+	requireNoPrepared(t, second)
+
+	var decisions int
+
+	err = first.QueryRow("SELECT count(*) FROM tx_coordinator_log").Scan(&decisions)
+	require.NoError(t, err)
+	require.Zero(t, decisions)
+}
+
+// Test_Coordinator_Participants checks that body sees transaction of every participant,
+// body fails, so no transaction is prepared and max_prepared_transactions isn't required.
+func Test_Coordinator_Participants(t *testing.T) {
+	t.Parallel()
+
+	first := postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil,
+		createUsersTableQuery,
+		pgxtx.CreateCoordinatorLogQuery,
+	)
+	second := postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil, createUsersTableQuery)
+
+	coordinator := pgxtx.NewCoordinator("participants", first,
+		[]pgxtx.Participant{
+			{Beginner: sqltx.NewBeginner(first), DB: first},
+			{Beginner: sqltx.NewBeginner(second), DB: second},
+		},
+	)
+
+	firstBeginner := sqltx.NewBeginner(first)
+	secondBeginner := sqltx.NewBeginner(second)
+
+	errStub := errors.New("stub")
+	userID := uuid.New()
+
+	err := tx.Run(context.Background(), coordinator,
+		func(txContext context.Context) error {
+			for _, beginner := range []*sqltx.Beginner{firstBeginner, secondBeginner} {
+				require.True(t, beginner.TxEnabled(txContext))
+
+				_, err := beginner.Executor(txContext).ExecContext(txContext, "INSERT INTO users (id, age) VALUES ($1, 1)", userID)
+				require.NoError(t, err)
+			}
+
+			return errStub
+		},
+		nil,
+	)
+	require.ErrorIs(t, err, errStub)
+
+	txtest.AssertUserNotFound(t, first, userID)
+	txtest.AssertUserNotFound(t, second, userID)
+}
+
+func Test_Coordinator_Recover(t *testing.T) {
+	t.Parallel()
+
+	first, second := twoPhaseDatabases(t)
+
+	coordinator := pgxtx.NewCoordinator("recover", first,
+		[]pgxtx.Participant{
+			{Beginner: sqltx.NewBeginner(first), DB: first},
+			{Beginner: sqltx.NewBeginner(second), DB: second},
+		},
+		pgxtx.WithRecoveryAge(time.Nanosecond),
+	)
+
+	ctx := context.Background()
+
+	prepare := func(db *sql.DB, gid string, userID uuid.UUID) {
+		conn, err := db.Conn(ctx)
+		require.NoError(t, err)
+
+		defer conn.Close()
+
+		_, err = conn.ExecContext(ctx, "BEGIN")
+		require.NoError(t, err)
+
+		_, err = conn.ExecContext(ctx, "INSERT INTO users (id, age) VALUES ($1, 1)", userID)
+		require.NoError(t, err)
+
+		_, err = conn.ExecContext(ctx, "PREPARE TRANSACTION '"+gid+"'")
+		require.NoError(t, err)
+	}
+
+	const (
+		decided   = "recover:0123456789abcdef0123456789abcdef"
+		undecided = "recover:fedcba9876543210fedcba9876543210"
+		// coordinator "recover:other" shares name prefix with "recover"
+		foreign = "recover:other:0123456789abcdef0123456789abcdef"
+	)
+
+	decidedID := uuid.New()
+	prepare(first, decided+":0", decidedID)
+	prepare(second, decided+":1", decidedID)
+
+	_, err := first.Exec("INSERT INTO tx_coordinator_log (id) VALUES ($1), ($2)", decided, foreign)
+	require.NoError(t, err)
+
+	undecidedID := uuid.New()
+	prepare(first, undecided+":0", undecidedID)
+
+	foreignID := uuid.New()
+	prepare(second, foreign+":1", foreignID)
+
+	err = coordinator.Recover(ctx)
+	require.NoError(t, err)
+
+	txtest.AssertUserExists(t, first, decidedID, 1)
+	txtest.AssertUserExists(t, second, decidedID, 1)
+	txtest.AssertUserNotFound(t, first, undecidedID)
+
+	var foreignDecision string
+
+	err = first.QueryRow("SELECT id FROM tx_coordinator_log").Scan(&foreignDecision)
+	require.NoError(t, err)
+	require.Equal(t, foreign, foreignDecision)
+
+	_, err = second.Exec("ROLLBACK PREPARED '" + foreign + ":1'")
+	require.NoError(t, err)
+
+	_, err = first.Exec("DELETE FROM tx_coordinator_log")
+	require.NoError(t, err)
+
+	requireNoPrepared(t, first)
+	requireNoPrepared(t, second)
+
+	var decisions int
+
+	err = first.QueryRow("SELECT count(*) FROM tx_coordinator_log").Scan(&decisions)
+	require.NoError(t, err)
+	require.Zero(t, decisions)
+}
@@ -0,0 +1,73 @@
+// Package queue implements Postgres job queue over the transactions of sql, sqlx and bun adapters.
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/amidgo/tx/internal/sqlctx"
+)
+
+var (
+	ErrNoTx = errors.New("queue: transaction not found in context")
+	// ErrMaxAttempts is recorded as the last error of job dead-lettered after its claims expired.
+	ErrMaxAttempts = errors.New("queue: max attempts exceeded")
+)
+
+// CreateTableQuery creates jobs table in Postgres database.
+const CreateTableQuery = `
+CREATE TABLE IF NOT EXISTS tx_queue (
+	id bigserial PRIMARY KEY,
+	queue text NOT NULL,
+	payload bytea NOT NULL,
+	status text NOT NULL DEFAULT 'pending',
+	attempts integer NOT NULL DEFAULT 0,
+	run_at timestamptz NOT NULL DEFAULT now(),
+	locked_until timestamptz,
+	last_error text,
+	created_at timestamptz NOT NULL DEFAULT now()
+)`
+
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusDone    Status = "done"
+	// StatusDead is status of dead-lettered job, it is never claimed again.
+	StatusDead Status = "dead"
+)
+
+type Job struct {
+	ID      int64
+	Queue   string
+	Payload []byte
+	// RunAt is a time job becomes available, now if zero.
+	RunAt time.Time
+	// Attempts is count of claims including current one.
+	Attempts  int
+	CreatedAt time.Time
+}
+
+// Enqueue stores job within transaction of txCtx, job becomes available only if the transaction commits.
+// tx.ErrAmbiguousTx is returned if txCtx holds transactions of several databases, e.g. inside tx.RunMulti.
+func Enqueue(txCtx context.Context, job Job) error {
+	tx, err := sqlctx.Current(txCtx)
+	if errors.Is(err, sqlctx.ErrNoTx) {
+		return ErrNoTx
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Tx.ExecContext(txCtx,
+		"INSERT INTO tx_queue (queue, payload, run_at) VALUES ($1, $2, COALESCE($3, now()))",
+		job.Queue,
+		job.Payload,
+		sql.NullTime{Time: job.RunAt, Valid: !job.RunAt.IsZero()},
+	)
+
+	return err
+}
@@ -0,0 +1,209 @@
+package queue_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	postgrescontainer "github.com/amidgo/containers/postgres"
+	"github.com/amidgo/containers/postgres/migrations"
+	"github.com/amidgo/tx"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amidgo/tx/internal/reusable"
+	txtest "github.com/amidgo/tx/internal/testing"
+	"github.com/amidgo/tx/queue"
+	sqltx "github.com/amidgo/tx/sql"
+)
+
+const createUsersTableQuery = `
+	CREATE TABLE users (
+		id uuid primary key,
+		age smallint not null
+	)
+`
+
+func requireJobStatus(t *testing.T, db *sql.DB, jobQueue string, status queue.Status, attempts int) {
+	var (
+		actualStatus   queue.Status
+		actualAttempts int
+	)
+
+	err := db.QueryRow("SELECT status, attempts FROM tx_queue WHERE queue = $1", jobQueue).Scan(&actualStatus, &actualAttempts)
+	require.NoError(t, err)
+	require.Equal(t, status, actualStatus)
+	require.Equal(t, attempts, actualAttempts)
+}
+
+func Test_Enqueue_NoTx(t *testing.T) {
+	t.Parallel()
+
+	err := queue.Enqueue(context.Background(), queue.Job{Queue: "users", Payload: []byte("payload")})
+	require.ErrorIs(t, err, queue.ErrNoTx)
+}
+
+func Test_Worker(t *testing.T) {
+	t.Parallel()
+
+	db := postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil,
+		createUsersTableQuery,
+		queue.CreateTableQuery,
+	)
+
+	beginner := sqltx.NewBeginner(db)
+
+	ctx := context.Background()
+
+	userID := uuid.New()
+	errRollback := errors.New("rollback")
+
+	err := tx.Run(ctx, beginner,
+		func(txContext context.Context) error {
+			err := queue.Enqueue(txContext, queue.Job{Queue: "users", Payload: []byte(uuid.NewString())})
+			require.NoError(t, err)
+
+			return errRollback
+		},
+		nil,
+	)
+	require.ErrorIs(t, err, errRollback)
+
+	err = tx.Run(ctx, beginner,
+		func(txContext context.Context) error {
+			return queue.Enqueue(txContext, queue.Job{Queue: "users", Payload: []byte(userID.String())})
+		},
+		nil,
+	)
+	require.NoError(t, err)
+
+	worker := queue.NewWorker(beginner, "users",
+		func(txContext context.Context, job queue.Job) error {
+			require.Equal(t, 1, job.Attempts)
+
+			_, err := beginner.Executor(txContext).ExecContext(txContext,
+				"INSERT INTO users (id, age) VALUES ($1, 1)",
+				string(job.Payload),
+			)
+
+			return err
+		},
+	)
+
+	processed, err := worker.ProcessNext(ctx)
+	require.NoError(t, err)
+	require.True(t, processed)
+
+	txtest.AssertUserExists(t, db, userID, 1)
+	requireJobStatus(t, db, "users", queue.StatusDone, 1)
+
+	processed, err = worker.ProcessNext(ctx)
+	require.NoError(t, err)
+	require.False(t, processed)
+}
+
+func Test_Worker_RetryAndDeadLetter(t *testing.T) {
+	t.Parallel()
+
+	db := postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil,
+		createUsersTableQuery,
+		queue.CreateTableQuery,
+	)
+
+	beginner := sqltx.NewBeginner(db)
+
+	ctx := context.Background()
+
+	err := tx.Run(ctx, beginner,
+		func(txContext context.Context) error {
+			return queue.Enqueue(txContext, queue.Job{Queue: "failing", Payload: []byte("payload")})
+		},
+		nil,
+	)
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	errHandle := errors.New("handle failed")
+
+	worker := queue.NewWorker(beginner, "failing",
+		func(txContext context.Context, job queue.Job) error {
+			_, err := beginner.Executor(txContext).ExecContext(txContext,
+				"INSERT INTO users (id, age) VALUES ($1, $2)",
+				userID, job.Attempts,
+			)
+			require.NoError(t, err)
+
+			return errHandle
+		},
+		queue.WithMaxAttempts(2),
+		queue.WithBackoff(func(int) time.Duration { return 0 }),
+	)
+
+	processed, err := worker.ProcessNext(ctx)
+	require.ErrorIs(t, err, errHandle)
+	require.True(t, processed)
+
+	txtest.AssertUserNotFound(t, db, userID)
+	requireJobStatus(t, db, "failing", queue.StatusPending, 1)
+
+	processed, err = worker.ProcessNext(ctx)
+	require.ErrorIs(t, err, errHandle)
+	require.True(t, processed)
+
+	txtest.AssertUserNotFound(t, db, userID)
+	requireJobStatus(t, db, "failing", queue.StatusDead, 2)
+
+	processed, err = worker.ProcessNext(ctx)
+	require.NoError(t, err)
+	require.False(t, processed)
+}
+
+func Test_Worker_VisibilityTimeout(t *testing.T) {
+	t.Parallel()
+
+	db := postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil, queue.CreateTableQuery)
+
+	beginner := sqltx.NewBeginner(db)
+
+	ctx := context.Background()
+
+	err := tx.Run(ctx, beginner,
+		func(txContext context.Context) error {
+			return queue.Enqueue(txContext, queue.Job{Queue: "crashed", Payload: []byte("payload")})
+		},
+		nil,
+	)
+	require.NoError(t, err)
+
+	// claim of the crashed worker
+	_, err = db.Exec("UPDATE tx_queue SET attempts = 1, locked_until = now() + interval '1 hour'")
+	require.NoError(t, err)
+
+	handled := 0
+
+	worker := queue.NewWorker(beginner, "crashed",
+		func(_ context.Context, job queue.Job) error {
+			handled++
+
+			require.Equal(t, 2, job.Attempts)
+
+			return nil
+		},
+	)
+
+	processed, err := worker.ProcessNext(ctx)
+	require.NoError(t, err)
+	require.False(t, processed)
+
+	_, err = db.Exec("UPDATE tx_queue SET locked_until = now() - interval '1 second'")
+	require.NoError(t, err)
+
+	processed, err = worker.ProcessNext(ctx)
+	require.NoError(t, err)
+	require.True(t, processed)
+	require.Equal(t, 1, handled)
+
+	requireJobStatus(t, db, "crashed", queue.StatusDone, 2)
+}
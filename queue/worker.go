@@ -0,0 +1,268 @@
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/amidgo/tx"
+	"github.com/amidgo/tx/internal/sqlctx"
+)
+
+// Handler processes job within transaction of txContext, job is marked done in the same transaction.
+type Handler func(txContext context.Context, job Job) error
+
+const (
+	defaultMaxAttempts       = 5
+	defaultVisibilityTimeout = 30 * time.Second
+	defaultPollInterval      = time.Second
+	defaultBackoffBase       = time.Second
+	defaultBackoffMax        = 10 * time.Minute
+)
+
+type WorkerOption func(*Worker)
+
+// WithMaxAttempts sets count of attempts before job is dead-lettered, 5 by default.
+func WithMaxAttempts(attempts int) WorkerOption {
+	return func(w *Worker) {
+		w.maxAttempts = attempts
+	}
+}
+
+// WithBackoff sets delay of the next attempt after failed attempt,
+// exponential from 1 second up to 10 minutes by default.
+func WithBackoff(backoff func(attempt int) time.Duration) WorkerOption {
+	return func(w *Worker) {
+		w.backoff = backoff
+	}
+}
+
+// WithVisibilityTimeout sets duration claimed job is hidden from other workers, 30 seconds by default.
+// Handler is canceled after it, job of crashed worker is claimed again after it.
+func WithVisibilityTimeout(timeout time.Duration) WorkerOption {
+	return func(w *Worker) {
+		w.visibilityTimeout = timeout
+	}
+}
+
+// WithPollInterval sets pause of Run when there is no available job, 1 second by default.
+func WithPollInterval(interval time.Duration) WorkerOption {
+	return func(w *Worker) {
+		w.pollInterval = interval
+	}
+}
+
+// WithLogger sets logger of Run errors, slog.Default by default.
+func WithLogger(logger *slog.Logger) WorkerOption {
+	return func(w *Worker) {
+		w.logger = logger
+	}
+}
+
+// WithRunOptions sets options of tx.Run of handler transaction.
+func WithRunOptions(opts ...tx.Option) WorkerOption {
+	return func(w *Worker) {
+		w.runOpts = opts
+	}
+}
+
+// Worker claims available jobs of the queue with SELECT ... FOR UPDATE SKIP LOCKED
+// and runs handler inside tx.Run of beginner, workers may run on several instances.
+type Worker struct {
+	beginner tx.Beginner
+	queue    string
+	handler  Handler
+
+	maxAttempts       int
+	backoff           func(attempt int) time.Duration
+	visibilityTimeout time.Duration
+	pollInterval      time.Duration
+	logger            *slog.Logger
+	runOpts           []tx.Option
+}
+
+// NewWorker creates worker, beginner must be an adapter beginner, optionally wrapped.
+func NewWorker(beginner tx.Beginner, queue string, handler Handler, opts ...WorkerOption) *Worker {
+	w := &Worker{
+		beginner:          beginner,
+		queue:             queue,
+		handler:           handler,
+		maxAttempts:       defaultMaxAttempts,
+		backoff:           exponentialBackoff,
+		visibilityTimeout: defaultVisibilityTimeout,
+		pollInterval:      defaultPollInterval,
+		logger:            slog.Default(),
+	}
+
+	for _, op := range opts {
+		op(w)
+	}
+
+	return w
+}
+
+func exponentialBackoff(attempt int) time.Duration {
+	backoff := defaultBackoffBase << min(max(attempt-1, 0), 32)
+
+	return min(backoff, defaultBackoffMax)
+}
+
+// Run processes jobs until ctx is done, errors are logged and processing continues.
+func (w *Worker) Run(ctx context.Context) error {
+	for {
+		processed, err := w.ProcessNext(ctx)
+		if err != nil {
+			w.logger.ErrorContext(ctx, "queue job failed", slog.String("queue", w.queue), slog.Any("error", err))
+		}
+
+		if processed {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(w.pollInterval):
+		}
+	}
+}
+
+// ProcessNext claims one available job and processes it, reports false if there was no available job.
+// Failed job is rescheduled after backoff or dead-lettered if it ran out of attempts.
+func (w *Worker) ProcessNext(ctx context.Context) (bool, error) {
+	job, claimed, err := w.claim(ctx)
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	if job.Attempts > w.maxAttempts {
+		return true, w.fail(ctx, job, ErrMaxAttempts)
+	}
+
+	handlerCtx, cancel := context.WithTimeout(ctx, w.visibilityTimeout)
+	defer cancel()
+
+	err = tx.Run(handlerCtx, w.beginner,
+		func(txContext context.Context) error {
+			exec, err := currentTx(txContext)
+			if err != nil {
+				return err
+			}
+
+			var id int64
+
+			// the claim is lost if it expired and job was claimed again
+			err = exec.QueryRowContext(txContext,
+				"SELECT id FROM tx_queue WHERE id = $1 AND attempts = $2 AND status = 'pending' FOR UPDATE",
+				job.ID,
+				job.Attempts,
+			).Scan(&id)
+			if err != nil {
+				return fmt.Errorf("lock claimed job, %w", err)
+			}
+
+			err = w.handler(txContext, job)
+			if err != nil {
+				return err
+			}
+
+			_, err = exec.ExecContext(txContext,
+				"UPDATE tx_queue SET status = 'done', locked_until = NULL, last_error = NULL WHERE id = $1",
+				job.ID,
+			)
+
+			return err
+		},
+		nil,
+		w.runOpts...,
+	)
+	if err != nil {
+		return true, errors.Join(fmt.Errorf("job %d, %w", job.ID, err), w.fail(ctx, job, err))
+	}
+
+	return true, nil
+}
+
+func (w *Worker) claim(ctx context.Context) (job Job, claimed bool, err error) {
+	err = tx.Run(ctx, w.beginner,
+		func(txContext context.Context) error {
+			exec, err := currentTx(txContext)
+			if err != nil {
+				return err
+			}
+
+			err = exec.QueryRowContext(txContext,
+				`UPDATE tx_queue SET attempts = attempts + 1, locked_until = now() + make_interval(secs => $2)
+				WHERE id = (
+					SELECT id FROM tx_queue
+					WHERE queue = $1 AND status = 'pending' AND run_at <= now() AND (locked_until IS NULL OR locked_until < now())
+					ORDER BY run_at, id
+					LIMIT 1
+					FOR UPDATE SKIP LOCKED
+				)
+				RETURNING id, queue, payload, run_at, attempts, created_at`,
+				w.queue,
+				w.visibilityTimeout.Seconds(),
+			).Scan(&job.ID, &job.Queue, &job.Payload, &job.RunAt, &job.Attempts, &job.CreatedAt)
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+
+			if err != nil {
+				return err
+			}
+
+			claimed = true
+
+			return nil
+		},
+		nil,
+	)
+
+	return job, claimed, err
+}
+
+// fail releases claim of job, reschedules it after backoff or dead-letters it.
+func (w *Worker) fail(ctx context.Context, job Job, jobErr error) error {
+	return tx.Run(context.WithoutCancel(ctx), w.beginner,
+		func(txContext context.Context) error {
+			exec, err := currentTx(txContext)
+			if err != nil {
+				return err
+			}
+
+			_, err = exec.ExecContext(txContext,
+				`UPDATE tx_queue SET
+					status = CASE WHEN attempts >= $3 THEN 'dead' ELSE 'pending' END,
+					run_at = now() + make_interval(secs => $4),
+					locked_until = NULL,
+					last_error = $5
+				WHERE id = $1 AND attempts = $2 AND status = 'pending'`,
+				job.ID,
+				job.Attempts,
+				w.maxAttempts,
+				w.backoff(job.Attempts).Seconds(),
+				jobErr.Error(),
+			)
+
+			return err
+		},
+		nil,
+	)
+}
+
+func currentTx(txContext context.Context) (*sql.Tx, error) {
+	tx, err := sqlctx.Current(txContext)
+	if errors.Is(err, sqlctx.ErrNoTx) {
+		return nil, ErrNoTx
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tx.Tx, nil
+}
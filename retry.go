@@ -0,0 +1,86 @@
+package tx
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrTransientRepeatTimesExceeded = errors.New("transient failure repeat times exceeded")
+
+// Backoff returns delay before retry attempt, attempts start from 1.
+type Backoff func(attempt int) time.Duration
+
+// ExponentialBackoff doubles delay from base with every attempt up to max.
+func ExponentialBackoff(base, max time.Duration) Backoff {
+	return func(attempt int) time.Duration {
+		delay := base
+
+		for i := 1; i < attempt && delay < max; i++ {
+			delay *= 2
+		}
+
+		return min(delay, max)
+	}
+}
+
+// RetryTransient re-runs Run up to times with backoff between attempts
+// on begin or withTx failure classified as ErrConnection by Driver,
+// negative times retries endlessly, nil backoff retries without delay.
+// Failures of commit are never retried, commit may have been applied.
+func RetryTransient(times int, backoff Backoff) Option {
+	return func(o *options) {
+		o.transientRetryCount = times
+		o.transientBackoff = backoff
+	}
+}
+
+func retryTransientExec(
+	ctx context.Context,
+	exec func() error,
+	retryCount int,
+	backoff Backoff,
+	budget *RetryBudget,
+) func() error {
+	return func() error {
+		err := exec()
+
+		for attempt := 1; transient(ctx, err); attempt++ {
+			if retryCount >= 0 && attempt > retryCount {
+				return errors.Join(ErrTransientRepeatTimesExceeded, err)
+			}
+
+			if !budget.withdraw() {
+				return errors.Join(ErrRetryBudgetExhausted, err)
+			}
+
+			if backoff != nil {
+				timer := time.NewTimer(backoff(attempt))
+
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+
+					return errors.Join(err, ctx.Err())
+				case <-timer.C:
+				}
+			}
+
+			err = exec()
+		}
+
+		return err
+	}
+}
+
+func transient(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+
+	if errors.Is(err, ErrCommit) || errors.Is(err, ErrPartialCommit) {
+		return false
+	}
+
+	return errors.Is(err, ErrConnection)
+}
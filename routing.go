@@ -0,0 +1,290 @@
+package tx
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type readOnlyKey struct{}
+
+// ReadOnlyContext marks ctx for non transactional reads, RoutingBeginner routes them to replicas.
+func ReadOnlyContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readOnlyKey{}, true)
+}
+
+func IsReadOnly(ctx context.Context) bool {
+	readOnly, _ := ctx.Value(readOnlyKey{}).(bool)
+
+	return readOnly
+}
+
+type ReplicaBalancer int
+
+const (
+	RoundRobin ReplicaBalancer = iota
+	// LeastBusy picks replica with the least number of open transactions.
+	LeastBusy
+)
+
+const defaultReplicaCooldown = 5 * time.Second
+
+type RoutingOption func(*RoutingBeginner)
+
+func WithReplicaBalancer(balancer ReplicaBalancer) RoutingOption {
+	return func(r *RoutingBeginner) {
+		r.balancer = balancer
+	}
+}
+
+// WithReplicaCooldown sets duration replica is considered unhealthy after failed begin, 5 seconds by default,
+// begin failed due to cancelled or expired ctx doesn't make replica unhealthy.
+func WithReplicaCooldown(cooldown time.Duration) RoutingOption {
+	return func(r *RoutingBeginner) {
+		r.cooldown = cooldown
+	}
+}
+
+// WithReplicaFilter skips replicas that don't pass filter for ctx of the routed call,
+// replica must pass every filter if the option is given several times.
+func WithReplicaFilter(filter func(ctx context.Context, replica Beginner) bool) RoutingOption {
+	return func(r *RoutingBeginner) {
+		prev := r.filter
+		if prev == nil {
+			r.filter = filter
+
+			return
+		}
+
+		r.filter = func(ctx context.Context, replica Beginner) bool {
+			return prev(ctx, replica) && filter(ctx, replica)
+		}
+	}
+}
+
+// OnPrimaryCommit adds hook called after successful commit of primary transactions
+// with ctx passed to BeginTx, hooks are called in order they were given.
+func OnPrimaryCommit(hook func(ctx context.Context)) RoutingOption {
+	return func(r *RoutingBeginner) {
+		prev := r.onPrimaryCommit
+		if prev == nil {
+			r.onPrimaryCommit = hook
+
+			return
+		}
+
+		r.onPrimaryCommit = func(ctx context.Context) {
+			prev(ctx)
+			hook(ctx)
+		}
+	}
+}
+
+type replica struct {
+	beginner       Beginner
+	openTxs        atomic.Int64
+	unhealthyUntil atomic.Int64
+}
+
+func (r *replica) healthy(now time.Time) bool {
+	return now.UnixNano() >= r.unhealthyUntil.Load()
+}
+
+var _ Beginner = (*RoutingBeginner)(nil)
+
+// RoutingBeginner begins read only transactions on a healthy replica and everything else on primary,
+// primary is used if no replica available.
+type RoutingBeginner struct {
+	primary  Beginner
+	replicas []*replica
+	balancer ReplicaBalancer
+	cooldown time.Duration
+	next     atomic.Uint64
+
+	filter          func(ctx context.Context, replica Beginner) bool
+	onPrimaryCommit func(ctx context.Context)
+}
+
+func NewRoutingBeginner(primary Beginner, replicas []Beginner, opts ...RoutingOption) *RoutingBeginner {
+	r := &RoutingBeginner{
+		primary:  primary,
+		replicas: make([]*replica, len(replicas)),
+		cooldown: defaultReplicaCooldown,
+	}
+
+	for i, beginner := range replicas {
+		r.replicas[i] = &replica{beginner: beginner}
+	}
+
+	for _, op := range opts {
+		op(r)
+	}
+
+	return r
+}
+
+func (r *RoutingBeginner) Begin(ctx context.Context) (Tx, error) {
+	tx, err := r.primary.Begin(ctx)
+
+	return r.primaryTx(ctx, tx, err)
+}
+
+func (r *RoutingBeginner) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	if opts == nil || !opts.ReadOnly {
+		tx, err := r.primary.BeginTx(ctx, opts)
+
+		return r.primaryTx(ctx, tx, err)
+	}
+
+	for _, rep := range r.healthyReplicas(ctx) {
+		tx, err := rep.beginner.BeginTx(ctx, opts)
+		if err != nil {
+			// failure caused by caller's ctx says nothing about replica health
+			if ctx.Err() != nil {
+				return nil, err
+			}
+
+			rep.unhealthyUntil.Store(time.Now().Add(r.cooldown).UnixNano())
+
+			continue
+		}
+
+		rep.openTxs.Add(1)
+
+		return &routedTx{
+			Tx:      tx,
+			release: func() { rep.openTxs.Add(-1) },
+		}, nil
+	}
+
+	tx, err := r.primary.BeginTx(ctx, opts)
+
+	return r.primaryTx(ctx, tx, err)
+}
+
+func (r *RoutingBeginner) primaryTx(ctx context.Context, tx Tx, err error) (Tx, error) {
+	if err != nil || r.onPrimaryCommit == nil {
+		return tx, err
+	}
+
+	return &routedTx{
+		Tx:       tx,
+		release:  func() {},
+		onCommit: func() { r.onPrimaryCommit(ctx) },
+	}, nil
+}
+
+// Route returns beginner for non transactional work with ctx,
+// a healthy replica if ctx is marked by ReadOnlyContext, primary otherwise.
+func (r *RoutingBeginner) Route(ctx context.Context) Beginner {
+	if !IsReadOnly(ctx) {
+		return r.primary
+	}
+
+	replicas := r.healthyReplicas(ctx)
+	if len(replicas) == 0 {
+		return r.primary
+	}
+
+	return replicas[0].beginner
+}
+
+func (r *RoutingBeginner) TxEnabled(ctx context.Context) bool {
+	if txEnabled(ctx, r.primary) {
+		return true
+	}
+
+	for _, rep := range r.replicas {
+		if txEnabled(ctx, rep.beginner) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *RoutingBeginner) RetryBudget() *RetryBudget {
+	return getRetryBudget(r.primary)
+}
+
+func (r *RoutingBeginner) Driver() Driver {
+	driver, _ := getDriver(r.primary)
+
+	return driver
+}
+
+// healthyReplicas returns healthy replicas passed filter in order of preference.
+func (r *RoutingBeginner) healthyReplicas(ctx context.Context) []*replica {
+	now := time.Now()
+
+	healthy := make([]*replica, 0, len(r.replicas))
+
+	available := func(rep *replica) bool {
+		if !rep.healthy(now) {
+			return false
+		}
+
+		return r.filter == nil || r.filter(ctx, rep.beginner)
+	}
+
+	switch r.balancer {
+	case LeastBusy:
+		for _, rep := range r.replicas {
+			if available(rep) {
+				healthy = append(healthy, rep)
+			}
+		}
+
+		slices.SortStableFunc(healthy, func(a, b *replica) int {
+			return cmp.Compare(a.openTxs.Load(), b.openTxs.Load())
+		})
+	default:
+		if len(r.replicas) == 0 {
+			return nil
+		}
+
+		start := int((r.next.Add(1) - 1) % uint64(len(r.replicas)))
+
+		for i := range r.replicas {
+			rep := r.replicas[(start+i)%len(r.replicas)]
+
+			if available(rep) {
+				healthy = append(healthy, rep)
+			}
+		}
+	}
+
+	return healthy
+}
+
+type routedTx struct {
+	Tx
+	release  func()
+	onCommit func()
+	once     sync.Once
+}
+
+func (r *routedTx) Commit() error {
+	defer r.once.Do(r.release)
+
+	err := r.Tx.Commit()
+	if err == nil && r.onCommit != nil {
+		r.onCommit()
+	}
+
+	return err
+}
+
+func (r *routedTx) Rollback() error {
+	defer r.once.Do(r.release)
+
+	return r.Tx.Rollback()
+}
+
+func (r *routedTx) State() TxState {
+	return State(r.Tx)
+}
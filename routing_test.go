@@ -0,0 +1,288 @@
+package tx_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/amidgo/tx"
+	txmocks "github.com/amidgo/tx/mocks"
+)
+
+func Test_RoutingBeginner(t *testing.T) {
+	readOnly := &sql.TxOptions{ReadOnly: true}
+	readWrite := &sql.TxOptions{Isolation: sql.LevelSerializable}
+
+	commit := func(t *testing.T, beginner tx.Beginner, opts *sql.TxOptions) {
+		err := tx.Run(context.Background(), beginner,
+			func(txContext context.Context) error {
+				checkTxEnabled(t, txContext)
+
+				return nil
+			},
+			opts,
+		)
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+	}
+
+	t.Run("read write, primary expected", func(t *testing.T) {
+		beginner := tx.NewRoutingBeginner(
+			txmocks.JoinBeginners(
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, readWrite),
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil),
+			)(t),
+			[]tx.Beginner{txmocks.ExpectNothing()(t)},
+		)
+
+		commit(t, beginner, readWrite)
+		commit(t, beginner, nil)
+	})
+
+	t.Run("read only, round robin", func(t *testing.T) {
+		replicaMock := txmocks.JoinBeginners(
+			txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, readOnly),
+			txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, readOnly),
+		)
+
+		beginner := tx.NewRoutingBeginner(
+			txmocks.ExpectNothing()(t),
+			[]tx.Beginner{replicaMock(t), replicaMock(t)},
+		)
+
+		for range 4 {
+			commit(t, beginner, readOnly)
+		}
+	})
+
+	t.Run("read only, replica failed, fallback to primary", func(t *testing.T) {
+		beginner := tx.NewRoutingBeginner(
+			txmocks.JoinBeginners(
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, readOnly),
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, readOnly),
+			)(t),
+			[]tx.Beginner{
+				txmocks.ExpectBeginTxAndReturnError(errors.New("replica down"), readOnly)(t),
+			},
+			tx.WithReplicaCooldown(time.Hour),
+		)
+
+		commit(t, beginner, readOnly)
+		commit(t, beginner, readOnly)
+	})
+
+	t.Run("read only, ctx canceled, replica stays healthy", func(t *testing.T) {
+		beginner := tx.NewRoutingBeginner(
+			txmocks.ExpectNothing()(t),
+			[]tx.Beginner{
+				txmocks.JoinBeginners(
+					txmocks.ExpectBeginTxAndReturnError(context.Canceled, readOnly),
+					txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, readOnly),
+				)(t),
+			},
+			tx.WithReplicaCooldown(time.Hour),
+		)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := beginner.BeginTx(ctx, readOnly)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected canceled error, actual %s", err)
+		}
+
+		commit(t, beginner, readOnly)
+	})
+
+	t.Run("read only, no replicas, primary expected", func(t *testing.T) {
+		beginner := tx.NewRoutingBeginner(
+			txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, readOnly)(t),
+			nil,
+		)
+
+		commit(t, beginner, readOnly)
+	})
+
+	t.Run("read only, least busy", func(t *testing.T) {
+		beginner := tx.NewRoutingBeginner(
+			txmocks.ExpectNothing()(t),
+			[]tx.Beginner{
+				txmocks.JoinBeginners(
+					txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, readOnly),
+					txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, readOnly),
+				)(t),
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, readOnly)(t),
+			},
+			tx.WithReplicaBalancer(tx.LeastBusy),
+		)
+
+		busyTx, err := beginner.BeginTx(context.Background(), readOnly)
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		commit(t, beginner, readOnly)
+
+		err = busyTx.Commit()
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		requireState(t, tx.StateCommitted, busyTx)
+
+		commit(t, beginner, readOnly)
+	})
+}
+
+func Test_RoutingBeginner_Route(t *testing.T) {
+	primary := txmocks.ExpectNothing()(t)
+	replica := txmocks.ExpectNothing()(t)
+
+	beginner := tx.NewRoutingBeginner(primary, []tx.Beginner{replica})
+
+	ctx := context.Background()
+
+	if beginner.Route(ctx) != primary {
+		t.Fatal("primary expected for ctx without read only mark")
+	}
+
+	if beginner.Route(tx.ReadOnlyContext(ctx)) != replica {
+		t.Fatal("replica expected for read only ctx")
+	}
+
+	if beginner.TxEnabled(ctx) {
+		t.Fatal("tx must not be enabled in empty ctx")
+	}
+
+	if !beginner.TxEnabled(txmocks.NilTx(t).Context()) {
+		t.Fatal("tx must be enabled in tx ctx")
+	}
+}
+
+func Test_RoutingBeginner_Driver(t *testing.T) {
+	errDriver := errors.New("driver")
+
+	beginner := tx.NewRoutingBeginner(
+		tx.BeginnerWithDriver(
+			txmocks.ExpectBeginTxAndReturnError(errors.New("begin"), nil)(t),
+			txmocks.ExpectDriverError(func(error, error) bool { return true }, nil, errDriver)(t),
+		),
+		nil,
+	)
+
+	err := tx.Run(context.Background(), beginner, func(context.Context) error { return nil }, nil)
+	if !errors.Is(err, errDriver) {
+		t.Fatalf("unexpected error, expected %s, actual %s", errDriver, err)
+	}
+}
+
+func Test_RoutingBeginner_ReplicaFilter(t *testing.T) {
+	readOnly := &sql.TxOptions{ReadOnly: true}
+
+	stale := txmocks.ExpectNothing()(t)
+	fresh := txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, readOnly)(t)
+
+	beginner := tx.NewRoutingBeginner(
+		txmocks.ExpectNothing()(t),
+		[]tx.Beginner{stale, fresh},
+		tx.WithReplicaFilter(func(_ context.Context, replica tx.Beginner) bool {
+			return replica != stale
+		}),
+	)
+
+	for range 2 {
+		if beginner.Route(tx.ReadOnlyContext(context.Background())) != fresh {
+			t.Fatal("fresh replica expected")
+		}
+	}
+
+	err := tx.Run(context.Background(), beginner, func(context.Context) error { return nil }, readOnly)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+}
+
+func Test_RoutingBeginner_Options_Combine(t *testing.T) {
+	readOnly := &sql.TxOptions{ReadOnly: true}
+
+	first := txmocks.ExpectNothing()(t)
+	second := txmocks.ExpectNothing()(t)
+	third := txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, readOnly)(t)
+
+	hooks := make([]string, 0)
+
+	beginner := tx.NewRoutingBeginner(
+		txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil)(t),
+		[]tx.Beginner{first, second, third},
+		tx.WithReplicaFilter(func(_ context.Context, replica tx.Beginner) bool { return replica != first }),
+		tx.OnPrimaryCommit(func(context.Context) { hooks = append(hooks, "first") }),
+		tx.WithReplicaFilter(func(_ context.Context, replica tx.Beginner) bool { return replica != second }),
+		tx.OnPrimaryCommit(func(context.Context) { hooks = append(hooks, "second") }),
+	)
+
+	err := tx.Run(context.Background(), beginner, func(context.Context) error { return nil }, readOnly)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+
+	err = tx.Run(context.Background(), beginner, func(context.Context) error { return nil }, nil)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+
+	if !slices.Equal(hooks, []string{"first", "second"}) {
+		t.Fatalf("every hook expected in order, called %v", hooks)
+	}
+}
+
+type commitKey struct{}
+
+func Test_RoutingBeginner_OnPrimaryCommit(t *testing.T) {
+	readOnly := &sql.TxOptions{ReadOnly: true}
+
+	commits := 0
+
+	beginner := tx.NewRoutingBeginner(
+		txmocks.JoinBeginners(
+			txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil),
+			txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollback(nil), nil),
+		)(t),
+		[]tx.Beginner{
+			txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, readOnly)(t),
+		},
+		tx.OnPrimaryCommit(func(ctx context.Context) {
+			if ctx.Value(commitKey{}) != true {
+				t.Fatal("ctx passed to BeginTx expected")
+			}
+
+			commits++
+		}),
+	)
+
+	ctx := context.WithValue(context.Background(), commitKey{}, true)
+
+	err := tx.Run(ctx, beginner, func(context.Context) error { return nil }, nil)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+
+	errRollback := errors.New("rollback")
+
+	err = tx.Run(ctx, beginner, func(context.Context) error { return errRollback }, nil)
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("unexpected error, expected %s, actual %s", errRollback, err)
+	}
+
+	err = tx.Run(ctx, beginner, func(context.Context) error { return nil }, readOnly)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+
+	if commits != 1 {
+		t.Fatalf("hook must be called once for primary commit, called %d", commits)
+	}
+}
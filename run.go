@@ -8,6 +8,12 @@ import (
 
 type options struct {
 	serializationRetryCount int
+	conflictRetryCount      int
+	transientRetryCount     int
+	transientBackoff        Backoff
+	verifyCommit            func(ctx context.Context) (bool, error)
+	retryBudget             *RetryBudget
+	propagation             Propagation
 }
 
 type Option func(*options)
@@ -18,6 +24,30 @@ func RetrySerialization(times int) Option {
 	}
 }
 
+// RetryConflicts re-runs the whole withTx on ErrConflict up to times.
+func RetryConflicts(times int) Option {
+	return func(o *options) {
+		o.conflictRetryCount = times
+	}
+}
+
+type Propagation int
+
+const (
+	// PropagationRequiresNew always begins a new transaction, default behaviour.
+	PropagationRequiresNew Propagation = iota
+	// PropagationRequired joins the transaction that is already present in ctx,
+	// a new transaction begins only if there is no one.
+	// The joined transaction is neither committed nor rolled back, it is owner's responsibility.
+	PropagationRequired
+)
+
+func WithPropagation(propagation Propagation) Option {
+	return func(o *options) {
+		o.propagation = propagation
+	}
+}
+
 func txPipelineExec(
 	ctx context.Context,
 	beginner Beginner,
@@ -25,6 +55,25 @@ func txPipelineExec(
 	txOpts *sql.TxOptions,
 	opts ...Option,
 ) func() error {
+	options := &options{}
+
+	for _, op := range opts {
+		op(options)
+	}
+
+	if options.propagation == PropagationRequired && txEnabled(ctx, beginner) {
+		return func() error {
+			return withTx(ctx)
+		}
+	}
+
+	runCtx := ctx
+
+	tracker, tracked := getRunTracker(beginner)
+	if tracked {
+		ctx = tracker.runContext(ctx)
+	}
+
 	pipeline := makeTxPipeline(ctx, beginner, withTx, txOpts)
 
 	driver, _ := getDriver(beginner)
@@ -35,14 +84,22 @@ func txPipelineExec(
 
 	exec := pipeline.exec()
 
-	options := &options{}
+	if options.verifyCommit != nil {
+		exec = verifyCommitExec(ctx, exec, options.verifyCommit)
+	}
 
-	for _, op := range opts {
-		op(options)
+	if options.retryBudget == nil {
+		options.retryBudget = getRetryBudget(beginner)
 	}
 
-	if options.serializationRetryCount != 0 {
-		exec = retrySerializationExec(exec, options.serializationRetryCount)
+	if options.transientRetryCount != 0 {
+		exec = retryTransientExec(ctx, exec, options.transientRetryCount, options.transientBackoff, options.retryBudget)
+	}
+
+	exec = retryBudgetExec(retryExec(exec, options), options.retryBudget)
+
+	if tracked {
+		exec = trackRunExec(runCtx, exec, tracker)
 	}
 
 	return exec
@@ -69,31 +126,47 @@ func withTxPipelineExec(
 		op(options)
 	}
 
+	return retryBudgetExec(retryExec(exec, options), options.retryBudget)
+}
+
+func retryExec(exec func() error, options *options) func() error {
 	if options.serializationRetryCount != 0 {
-		exec = retrySerializationExec(exec, options.serializationRetryCount)
+		exec = retryErrorExec(exec, options.serializationRetryCount, ErrSerialization, ErrSerializationRepeatTimesExcedeed, options.retryBudget)
+	}
+
+	if options.conflictRetryCount != 0 {
+		exec = retryErrorExec(exec, options.conflictRetryCount, ErrConflict, ErrConflictRepeatTimesExceeded, options.retryBudget)
 	}
 
 	return exec
 }
 
-func retrySerializationExec(exec func() error, serializationRetryCount int) func() error {
+func retryErrorExec(exec func() error, retryCount int, target, exceeded error, budget *RetryBudget) func() error {
+	retryable := func(err error) bool {
+		return errors.Is(err, target) && !errors.Is(err, ErrPartialCommit)
+	}
+
 	return func() error {
 		err := exec()
-		if !errors.Is(err, ErrSerialization) {
+		if !retryable(err) {
 			return err
 		}
 
-		for i := serializationRetryCount; i != 0; i-- {
+		for i := retryCount; i != 0; i-- {
+			if !budget.withdraw() {
+				return errors.Join(ErrRetryBudgetExhausted, err)
+			}
+
 			err = exec()
 
-			if errors.Is(err, ErrSerialization) {
+			if retryable(err) {
 				continue
 			}
 
 			return err
 		}
 
-		return errors.Join(ErrSerializationRepeatTimesExcedeed, err)
+		return errors.Join(exceeded, err)
 	}
 }
 
@@ -113,7 +186,7 @@ func useDriverToTxPipeline(pipeline txPipeline, driver Driver) txPipeline {
 		commit: func(tx Tx) error {
 			err := pipeline.commit(tx)
 
-			return driverError(driver, err)
+			return commitDriverError(driver, err)
 		},
 		rollback: func(tx Tx) error {
 			err := pipeline.rollback(tx)
@@ -129,9 +202,13 @@ func makeTxPipeline(
 	withTx func(txContext context.Context) error,
 	txOpts *sql.TxOptions,
 ) txPipeline {
+	attempt := 0
+
 	return txPipeline{
 		begin: func() (Tx, error) {
-			return beginner.BeginTx(ctx, txOpts)
+			attempt++
+
+			return beginner.BeginTx(contextWithAttempt(ctx, attempt), txOpts)
 		},
 		withTx: withTx,
 		commit: func(tx Tx) error {
@@ -225,7 +302,10 @@ func Run(
 	)()
 }
 
-var ErrSerializationRepeatTimesExcedeed = errors.New("serialization repeat times exceeded")
+var (
+	ErrSerializationRepeatTimesExcedeed = errors.New("serialization repeat times exceeded")
+	ErrConflictRepeatTimesExceeded      = errors.New("conflict repeat times exceeded")
+)
 
 func driverError(driver Driver, err error) error {
 	if err == nil {
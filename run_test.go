@@ -5,7 +5,9 @@ import (
 	"database/sql"
 	"errors"
 	"io"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/amidgo/tx"
 	txmocks "github.com/amidgo/tx/mocks"
@@ -483,3 +485,302 @@ func checkTxEnabled(t *testing.T, ctx context.Context) {
 		t.Fatal("check tx fail, mocks.TxDisabled matches ctx")
 	}
 }
+
+func Test_Run_Propagation(t *testing.T) {
+	t.Run("required, tx in ctx, join expected", func(t *testing.T) {
+		ctx := txmocks.NilTx(t).Context()
+
+		called := false
+
+		err := tx.Run(ctx,
+			tx.BeginnerWithDriver(txmocks.ExpectNothing()(t), txmocks.NilDriver(t)),
+			func(txContext context.Context) error {
+				called = true
+
+				if txContext != ctx {
+					t.Fatal("joined tx context must be the same as outer context")
+				}
+
+				return nil
+			},
+			nil,
+			tx.WithPropagation(tx.PropagationRequired),
+		)
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		if !called {
+			t.Fatal("withTx not called")
+		}
+	})
+
+	t.Run("required, no tx in ctx, begin expected", func(t *testing.T) {
+		err := tx.Run(context.Background(),
+			txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil)(t),
+			func(txContext context.Context) error {
+				checkTxEnabled(t, txContext)
+
+				return nil
+			},
+			nil,
+			tx.WithPropagation(tx.PropagationRequired),
+		)
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+	})
+
+	t.Run("required, joined withTx failed, error returned as is", func(t *testing.T) {
+		errWithTx := errors.New("with tx")
+
+		err := tx.Run(txmocks.NilTx(t).Context(),
+			txmocks.ExpectNothing()(t),
+			func(context.Context) error { return errWithTx },
+			nil,
+			tx.WithPropagation(tx.PropagationRequired),
+			tx.RetrySerialization(-1),
+		)
+		if !errors.Is(err, errWithTx) {
+			t.Fatalf("unexpected error, expected %s, actual %s", errWithTx, err)
+		}
+	})
+
+	t.Run("requires new, tx in ctx, begin expected", func(t *testing.T) {
+		err := tx.Run(txmocks.NilTx(t).Context(),
+			txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil)(t),
+			func(txContext context.Context) error {
+				checkTxEnabled(t, txContext)
+
+				return nil
+			},
+			nil,
+			tx.WithPropagation(tx.PropagationRequiresNew),
+		)
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+	})
+}
+
+func Test_Run_RetryConflicts(t *testing.T) {
+	withTx := func(count int) func(t *testing.T, ctx context.Context) error {
+		called := 0
+
+		return func(t *testing.T, ctx context.Context) error {
+			checkTxEnabled(t, ctx)
+
+			if called == count {
+				return nil
+			}
+
+			called++
+
+			return tx.ErrConflict
+		}
+	}
+
+	conflictDriver := txmocks.ExpectDriverError(errors.Is, tx.ErrConflict, tx.ErrConflict)
+
+	tests := []*runDriverTest{
+		{
+			Name:         "conflict, no opts provided",
+			DriverMock:   conflictDriver,
+			BeginnerMock: txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollback(nil), nil),
+			WithTx:       withTx(1),
+			Opts: []tx.Option{
+				tx.RetrySerialization(3),
+			},
+			ExpectedErrors:   []error{tx.ErrConflict},
+			UnexpectedErrors: []error{tx.ErrConflictRepeatTimesExceeded},
+		},
+		{
+			Name:       "conflict, retried",
+			DriverMock: txmocks.JoinDrivers(conflictDriver, conflictDriver),
+			BeginnerMock: txmocks.JoinBeginners(
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollback(nil), nil),
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollback(nil), nil),
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil),
+			),
+			WithTx: withTx(2),
+			Opts: []tx.Option{
+				tx.RetryConflicts(2),
+			},
+		},
+		{
+			Name:       "conflict, retry times exceeded",
+			DriverMock: txmocks.JoinDrivers(conflictDriver, conflictDriver),
+			BeginnerMock: txmocks.JoinBeginners(
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollback(nil), nil),
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollback(nil), nil),
+			),
+			WithTx: withTx(2),
+			Opts: []tx.Option{
+				tx.RetryConflicts(1),
+			},
+			ExpectedErrors: []error{tx.ErrConflict, tx.ErrConflictRepeatTimesExceeded},
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, tst.Test)
+	}
+}
+
+func Test_ExecVersioned_NoSQLTx(t *testing.T) {
+	err := tx.ExecVersioned(txmocks.NilTx(t).Context(), "UPDATE users SET version = version + 1 WHERE id = $1 AND version = $2", 1, 1)
+	if !errors.Is(err, tx.ErrVersionedNoSQLTx) {
+		t.Fatalf("unexpected error, expected %s, actual %v", tx.ErrVersionedNoSQLTx, err)
+	}
+}
+
+func Test_Run_RetryTransient(t *testing.T) {
+	connectionDriver := txmocks.ExpectDriverError(errors.Is, io.ErrUnexpectedEOF, errors.Join(tx.ErrConnection, io.ErrUnexpectedEOF))
+
+	tests := []*runDriverTest{
+		{
+			Name:       "begin failed, retried",
+			DriverMock: txmocks.JoinDrivers(connectionDriver, connectionDriver),
+			BeginnerMock: txmocks.JoinBeginners(
+				txmocks.ExpectBeginTxAndReturnError(io.ErrUnexpectedEOF, nil),
+				txmocks.ExpectBeginTxAndReturnError(io.ErrUnexpectedEOF, nil),
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil),
+			),
+			WithTx: func(*testing.T, context.Context) error { return nil },
+			Opts: []tx.Option{
+				tx.RetryTransient(2, nil),
+			},
+		},
+		{
+			Name:       "begin failed, retry times exceeded",
+			DriverMock: txmocks.JoinDrivers(connectionDriver, connectionDriver),
+			BeginnerMock: txmocks.JoinBeginners(
+				txmocks.ExpectBeginTxAndReturnError(io.ErrUnexpectedEOF, nil),
+				txmocks.ExpectBeginTxAndReturnError(io.ErrUnexpectedEOF, nil),
+			),
+			WithTx: func(*testing.T, context.Context) error { return nil },
+			Opts: []tx.Option{
+				tx.RetryTransient(1, nil),
+			},
+			ExpectedErrors: []error{tx.ErrBeginTx, tx.ErrTransientRepeatTimesExceeded},
+		},
+		{
+			Name: "begin failed, not connection, not retried",
+			DriverMock: txmocks.ExpectDriverError(
+				errors.Is,
+				tx.ErrShardKeyMissing,
+				tx.ErrShardKeyMissing,
+			),
+			BeginnerMock: txmocks.ExpectBeginTxAndReturnError(tx.ErrShardKeyMissing, nil),
+			WithTx:       func(*testing.T, context.Context) error { return nil },
+			Opts: []tx.Option{
+				tx.RetryTransient(3, nil),
+			},
+			ExpectedErrors:   []error{tx.ErrBeginTx, tx.ErrShardKeyMissing},
+			UnexpectedErrors: []error{tx.ErrTransientRepeatTimesExceeded},
+		},
+		{
+			Name:       "withTx lost connection, retried",
+			DriverMock: connectionDriver,
+			BeginnerMock: txmocks.JoinBeginners(
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollback(nil), nil),
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil),
+			),
+			WithTx: func() func(*testing.T, context.Context) error {
+				called := 0
+
+				return func(t *testing.T, ctx context.Context) error {
+					called++
+					if called == 1 {
+						return io.ErrUnexpectedEOF
+					}
+
+					return nil
+				}
+			}(),
+			Opts: []tx.Option{
+				tx.RetryTransient(1, nil),
+			},
+		},
+		{
+			Name: "withTx failed, not connection, not retried",
+			DriverMock: txmocks.ExpectDriverError(
+				errors.Is,
+				io.ErrUnexpectedEOF,
+				io.ErrUnexpectedEOF,
+			),
+			BeginnerMock: txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollback(nil), nil),
+			WithTx:       func(*testing.T, context.Context) error { return io.ErrUnexpectedEOF },
+			Opts: []tx.Option{
+				tx.RetryTransient(3, nil),
+			},
+			ExpectedErrors: []error{io.ErrUnexpectedEOF},
+		},
+		{
+			Name:       "commit lost connection, never retried",
+			DriverMock: connectionDriver,
+			BeginnerMock: txmocks.ExpectBeginTxAndReturnTx(
+				txmocks.ExpectRollbackAfterFailedCommit(io.ErrUnexpectedEOF),
+				nil,
+			),
+			WithTx: func(*testing.T, context.Context) error { return nil },
+			Opts: []tx.Option{
+				tx.RetryTransient(3, nil),
+			},
+			ExpectedErrors:   []error{tx.ErrCommit, tx.ErrConnection},
+			UnexpectedErrors: []error{tx.ErrTransientRepeatTimesExceeded},
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, tst.Test)
+	}
+}
+
+func Test_Run_RetryTransient_Backoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	attempts := make([]int, 0)
+
+	connectionDriver := txmocks.ExpectDriverError(errors.Is, io.ErrUnexpectedEOF, errors.Join(tx.ErrConnection, io.ErrUnexpectedEOF))
+
+	err := tx.Run(ctx,
+		tx.BeginnerWithDriver(
+			txmocks.JoinBeginners(
+				txmocks.ExpectBeginTxAndReturnError(io.ErrUnexpectedEOF, nil),
+				txmocks.ExpectBeginTxAndReturnError(io.ErrUnexpectedEOF, nil),
+			)(t),
+			txmocks.JoinDrivers(connectionDriver, connectionDriver)(t),
+		),
+		func(context.Context) error { return nil },
+		nil,
+		tx.RetryTransient(-1, func(attempt int) time.Duration {
+			attempts = append(attempts, attempt)
+			if attempt == 2 {
+				cancel()
+			}
+
+			return time.Millisecond
+		}),
+	)
+	if !errors.Is(err, tx.ErrBeginTx) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected error, %+v", err)
+	}
+
+	if !slices.Equal(attempts, []int{1, 2}) {
+		t.Fatalf("unexpected backoff attempts, %v", attempts)
+	}
+}
+
+func Test_ExponentialBackoff(t *testing.T) {
+	backoff := tx.ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond)
+
+	expected := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 50 * time.Millisecond, 50 * time.Millisecond}
+
+	for i, delay := range expected {
+		if backoff(i+1) != delay {
+			t.Fatalf("unexpected delay of attempt %d, expected %s, actual %s", i+1, delay, backoff(i+1))
+		}
+	}
+}
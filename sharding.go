@@ -0,0 +1,137 @@
+package tx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+)
+
+var (
+	ErrShardKeyMissing = errors.New("shard key missing in context")
+	ErrCrossShard      = errors.New("transaction touches a second shard")
+)
+
+type shardKey struct{}
+
+// ContextWithShardKey places key used by ShardedBeginner to pick a shard into ctx.
+func ContextWithShardKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, shardKey{}, key)
+}
+
+func ShardKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(shardKey{}).(string)
+
+	return key, ok
+}
+
+// ShardFunc maps shard key to index of the shard.
+type ShardFunc func(key string) int
+
+// HashShard spreads keys over shards count by FNV-1a hash of the key,
+// non-positive shards count maps every key to -1, so ShardedBeginner.Shard fails.
+func HashShard(shards int) ShardFunc {
+	return func(key string) int {
+		if shards <= 0 {
+			return -1
+		}
+
+		h := fnv.New32a()
+		h.Write([]byte(key))
+
+		return int(h.Sum32() % uint32(shards))
+	}
+}
+
+var _ Beginner = (*ShardedBeginner)(nil)
+
+// ShardedBeginner delegates to the shard picked by ShardFunc for shard key of ctx.
+// Work of ctx with transaction of one shard is refused on any other shard with ErrCrossShard.
+type ShardedBeginner struct {
+	shards []Beginner
+	shard  ShardFunc
+}
+
+func NewShardedBeginner(shards []Beginner, shard ShardFunc) *ShardedBeginner {
+	return &ShardedBeginner{
+		shards: shards,
+		shard:  shard,
+	}
+}
+
+func (s *ShardedBeginner) Begin(ctx context.Context) (Tx, error) {
+	beginner, err := s.Shard(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return beginner.Begin(ctx)
+}
+
+func (s *ShardedBeginner) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	beginner, err := s.Shard(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return beginner.BeginTx(ctx, opts)
+}
+
+// Shard returns beginner of the shard for ctx.
+// Without shard key in ctx shard of the ctx transaction is returned, ErrShardKeyMissing if there is no such.
+func (s *ShardedBeginner) Shard(ctx context.Context) (Beginner, error) {
+	active := s.activeShard(ctx)
+
+	key, ok := ShardKeyFromContext(ctx)
+	if !ok {
+		if active < 0 {
+			return nil, ErrShardKeyMissing
+		}
+
+		return s.shards[active], nil
+	}
+
+	index := s.shard(key)
+	if index < 0 || index >= len(s.shards) {
+		return nil, fmt.Errorf("shard key %q mapped to shard %d out of %d shards", key, index, len(s.shards))
+	}
+
+	if active >= 0 && active != index {
+		return nil, fmt.Errorf("%w, key %q mapped to shard %d, transaction began on shard %d", ErrCrossShard, key, index, active)
+	}
+
+	return s.shards[index], nil
+}
+
+func (s *ShardedBeginner) TxEnabled(ctx context.Context) bool {
+	return s.activeShard(ctx) >= 0
+}
+
+func (s *ShardedBeginner) Driver() Driver {
+	if len(s.shards) == 0 {
+		return nil
+	}
+
+	driver, _ := getDriver(s.shards[0])
+
+	return driver
+}
+
+func (s *ShardedBeginner) RetryBudget() *RetryBudget {
+	if len(s.shards) == 0 {
+		return nil
+	}
+
+	return getRetryBudget(s.shards[0])
+}
+
+func (s *ShardedBeginner) activeShard(ctx context.Context) int {
+	for i, beginner := range s.shards {
+		if txEnabled(ctx, beginner) {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,163 @@
+package tx_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/amidgo/tx"
+	txmocks "github.com/amidgo/tx/mocks"
+)
+
+type shardTxKey struct{}
+
+// shardStub tells transactions of own shard apart, unlike mocks sharing one transaction key.
+type shardStub struct {
+	tx.Beginner
+}
+
+func (s *shardStub) Begin(ctx context.Context) (tx.Tx, error) {
+	return s.wrap(s.Beginner.Begin(ctx))
+}
+
+func (s *shardStub) BeginTx(ctx context.Context, opts *sql.TxOptions) (tx.Tx, error) {
+	return s.wrap(s.Beginner.BeginTx(ctx, opts))
+}
+
+func (s *shardStub) wrap(transaction tx.Tx, err error) (tx.Tx, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return &shardTx{
+		Tx:  transaction,
+		ctx: context.WithValue(transaction.Context(), shardTxKey{}, s),
+	}, nil
+}
+
+func (s *shardStub) TxEnabled(ctx context.Context) bool {
+	return ctx.Value(shardTxKey{}) == s
+}
+
+type shardTx struct {
+	tx.Tx
+	ctx context.Context
+}
+
+func (s *shardTx) Context() context.Context {
+	return s.ctx
+}
+
+func shardByIndex(key string) int {
+	index, _ := strconv.Atoi(key)
+
+	return index
+}
+
+func Test_ShardedBeginner(t *testing.T) {
+	t.Run("begin on shard of key", func(t *testing.T) {
+		beginner := tx.NewShardedBeginner(
+			[]tx.Beginner{
+				txmocks.ExpectNothing()(t),
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil)(t),
+			},
+			shardByIndex,
+		)
+
+		ctx := tx.ContextWithShardKey(context.Background(), "1")
+
+		err := tx.Run(ctx, beginner, func(context.Context) error { return nil }, nil)
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+	})
+
+	t.Run("shard key missing", func(t *testing.T) {
+		beginner := tx.NewShardedBeginner([]tx.Beginner{txmocks.ExpectNothing()(t)}, shardByIndex)
+
+		_, err := beginner.Begin(context.Background())
+		if !errors.Is(err, tx.ErrShardKeyMissing) {
+			t.Fatalf("unexpected error, expected %s, actual %v", tx.ErrShardKeyMissing, err)
+		}
+	})
+
+	t.Run("shard out of range", func(t *testing.T) {
+		beginner := tx.NewShardedBeginner([]tx.Beginner{txmocks.ExpectNothing()(t)}, shardByIndex)
+
+		_, err := beginner.Begin(tx.ContextWithShardKey(context.Background(), "2"))
+		if err == nil {
+			t.Fatal("expected error for shard out of range")
+		}
+	})
+
+	t.Run("second shard touched in transaction", func(t *testing.T) {
+		first := &shardStub{Beginner: txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil)(t)}
+		second := &shardStub{Beginner: txmocks.ExpectNothing()(t)}
+
+		beginner := tx.NewShardedBeginner([]tx.Beginner{first, second}, shardByIndex)
+
+		ctx := tx.ContextWithShardKey(context.Background(), "0")
+
+		err := tx.Run(ctx, beginner,
+			func(txContext context.Context) error {
+				if !beginner.TxEnabled(txContext) {
+					t.Fatal("tx must be enabled in tx ctx")
+				}
+
+				shard, err := beginner.Shard(txContext)
+				if err != nil || shard != first {
+					t.Fatalf("first shard expected, actual %v, %v", shard, err)
+				}
+
+				shard, err = beginner.Shard(tx.ContextWithShardKey(txContext, "0"))
+				if err != nil || shard != first {
+					t.Fatalf("first shard expected, actual %v, %v", shard, err)
+				}
+
+				_, err = beginner.Shard(tx.ContextWithShardKey(txContext, "1"))
+				if !errors.Is(err, tx.ErrCrossShard) {
+					t.Fatalf("unexpected error, expected %s, actual %v", tx.ErrCrossShard, err)
+				}
+
+				_, err = beginner.Begin(tx.ContextWithShardKey(txContext, "1"))
+				if !errors.Is(err, tx.ErrCrossShard) {
+					t.Fatalf("unexpected error, expected %s, actual %v", tx.ErrCrossShard, err)
+				}
+
+				return nil
+			},
+			nil,
+		)
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+	})
+}
+
+func Test_HashShard(t *testing.T) {
+	shard := tx.HashShard(3)
+
+	for i := range 100 {
+		key := strconv.Itoa(i)
+
+		index := shard(key)
+		if index < 0 || index >= 3 {
+			t.Fatalf("shard index %d out of range", index)
+		}
+
+		if shard(key) != index {
+			t.Fatalf("shard of key %s must be stable", key)
+		}
+	}
+}
+
+func Test_HashShard_NoShards(t *testing.T) {
+	beginner := tx.NewShardedBeginner(nil, tx.HashShard(0))
+
+	_, err := beginner.Shard(tx.ContextWithShardKey(context.Background(), "key"))
+	if err == nil {
+		t.Fatal("expected error of shard key mapped out of shards")
+	}
+}
@@ -0,0 +1,283 @@
+package tx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+var ErrShuttingDown = errors.New("beginner is shutting down")
+
+type shutdownState int
+
+const (
+	shutdownRunning shutdownState = iota
+	shutdownDraining
+	shutdownClosed
+)
+
+type shutdownRunKey struct{}
+
+// runTracker is implemented by beginners that track Run calls,
+// Run registers itself for the whole run including retries.
+type runTracker interface {
+	runContext(ctx context.Context) context.Context
+	enterRun(ctx context.Context) (exit func(), err error)
+}
+
+// getRunTracker finds runTracker of x, wrapping beginners forward tracker of the wrapped one.
+func getRunTracker(x any) (runTracker, bool) {
+	tracked, ok := x.(interface{ tracker() (runTracker, bool) })
+	if !ok {
+		return nil, false
+	}
+
+	return tracked.tracker()
+}
+
+var _ Beginner = (*ShutdownBeginner)(nil)
+
+// ShutdownBeginner refuses new transactions with ErrShuttingDown after Shutdown is called
+// and tracks in-flight ones to drain them.
+// Run with ShutdownBeginner started before Shutdown keeps beginning transactions of its retries
+// and of nested calls with its txContext until Shutdown ctx expires.
+type ShutdownBeginner struct {
+	beginner Beginner
+
+	mu      sync.Mutex
+	state   shutdownState
+	runs    int
+	begins  int
+	txs     map[*shutdownTx]struct{}
+	drained chan struct{}
+}
+
+func NewShutdownBeginner(beginner Beginner) *ShutdownBeginner {
+	return &ShutdownBeginner{
+		beginner: beginner,
+		txs:      make(map[*shutdownTx]struct{}),
+		drained:  make(chan struct{}),
+	}
+}
+
+func (s *ShutdownBeginner) Begin(ctx context.Context) (Tx, error) {
+	return s.begin(ctx, s.beginner.Begin)
+}
+
+func (s *ShutdownBeginner) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	return s.begin(ctx, func(ctx context.Context) (Tx, error) {
+		return s.beginner.BeginTx(ctx, opts)
+	})
+}
+
+// Shutdown refuses new transactions and waits until in-flight transactions and Run calls finish.
+// When ctx expires remaining transactions are rolled back and ctx error is returned
+// joined with rollback errors.
+func (s *ShutdownBeginner) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+
+	if s.state == shutdownRunning {
+		s.state = shutdownDraining
+		s.checkDrained()
+	}
+
+	s.mu.Unlock()
+
+	select {
+	case <-s.drained:
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+
+	s.state = shutdownClosed
+
+	txs := make([]*shutdownTx, 0, len(s.txs))
+	for tx := range s.txs {
+		txs = append(txs, tx)
+	}
+
+	s.mu.Unlock()
+
+	errs := []error{ctx.Err()}
+
+	for _, tx := range txs {
+		errs = append(errs, tx.Rollback())
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *ShutdownBeginner) ShuttingDown() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.state != shutdownRunning
+}
+
+func (s *ShutdownBeginner) Driver() Driver {
+	driver, _ := getDriver(s.beginner)
+
+	return driver
+}
+
+func (s *ShutdownBeginner) RetryBudget() *RetryBudget {
+	return getRetryBudget(s.beginner)
+}
+
+func (s *ShutdownBeginner) TxEnabled(ctx context.Context) bool {
+	return txEnabled(ctx, s.beginner)
+}
+
+func (s *ShutdownBeginner) tracker() (runTracker, bool) {
+	return s, true
+}
+
+func (s *ShutdownBeginner) begin(ctx context.Context, begin func(ctx context.Context) (Tx, error)) (Tx, error) {
+	s.mu.Lock()
+
+	if !s.allowed(ctx) {
+		s.mu.Unlock()
+
+		return nil, ErrShuttingDown
+	}
+
+	s.begins++
+
+	s.mu.Unlock()
+
+	tx, err := begin(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.begins--
+
+	if err != nil {
+		s.checkDrained()
+
+		return nil, err
+	}
+
+	if s.state == shutdownClosed {
+		_ = tx.Rollback()
+
+		return nil, ErrShuttingDown
+	}
+
+	stx := &shutdownTx{Tx: tx, beginner: s}
+	s.txs[stx] = struct{}{}
+
+	return stx, nil
+}
+
+// allowed must be called with s.mu held.
+func (s *ShutdownBeginner) allowed(ctx context.Context) bool {
+	switch s.state {
+	case shutdownRunning:
+		return true
+	case shutdownDraining:
+		run, _ := ctx.Value(shutdownRunKey{}).(*ShutdownBeginner)
+
+		return run == s
+	default:
+		return false
+	}
+}
+
+func (s *ShutdownBeginner) runContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, shutdownRunKey{}, s)
+}
+
+// enterRun registers Run with ctx, nested Run with txContext of in-flight one is allowed while draining.
+func (s *ShutdownBeginner) enterRun(ctx context.Context) (func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.allowed(ctx) {
+		return nil, ErrShuttingDown
+	}
+
+	s.runs++
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.runs--
+		s.checkDrained()
+	}, nil
+}
+
+func (s *ShutdownBeginner) release(tx *shutdownTx) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.txs, tx)
+	s.checkDrained()
+}
+
+// checkDrained must be called with s.mu held.
+func (s *ShutdownBeginner) checkDrained() {
+	if s.state != shutdownDraining || s.runs != 0 || s.begins != 0 || len(s.txs) != 0 {
+		return
+	}
+
+	select {
+	case <-s.drained:
+	default:
+		close(s.drained)
+	}
+}
+
+type shutdownTx struct {
+	Tx
+	beginner *ShutdownBeginner
+	once     sync.Once
+}
+
+func (s *shutdownTx) Commit() error {
+	defer s.once.Do(s.release)
+
+	return s.Tx.Commit()
+}
+
+func (s *shutdownTx) Rollback() error {
+	defer s.once.Do(s.release)
+
+	return s.Tx.Rollback()
+}
+
+// Context marks txContext, so nested Run and begins with it are let through while draining.
+func (s *shutdownTx) Context() context.Context {
+	return s.beginner.runContext(s.Tx.Context())
+}
+
+func (s *shutdownTx) release() {
+	s.beginner.release(s)
+}
+
+func (s *shutdownTx) State() TxState {
+	return State(s.Tx)
+}
+
+func (s *shutdownTx) Driver() Driver {
+	driver, _ := getDriver(s.Tx)
+
+	return driver
+}
+
+func trackRunExec(ctx context.Context, exec func() error, tracker runTracker) func() error {
+	return func() error {
+		exit, err := tracker.enterRun(ctx)
+		if err != nil {
+			return errors.Join(ErrBeginTx, err)
+		}
+
+		defer exit()
+
+		return exec()
+	}
+}
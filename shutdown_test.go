@@ -0,0 +1,224 @@
+package tx_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/amidgo/tx"
+	txmocks "github.com/amidgo/tx/mocks"
+)
+
+func Test_ShutdownBeginner_Drain(t *testing.T) {
+	shutdown := newDrainShutdownBeginner(t)
+
+	testShutdownDrain(t, shutdown, shutdown)
+}
+
+func Test_ShutdownBeginner_Drain_Wrapped(t *testing.T) {
+	shutdown := newDrainShutdownBeginner(t)
+
+	beginner := tx.NewBreakerBeginner(
+		tx.BeginnerWithRetryBudget(
+			tx.NewLimitedBeginner(
+				tx.NewWatchdogBeginner(shutdown, time.Hour, func(tx.LongTransaction) {}),
+				2,
+			),
+			tx.NewRetryBudget(1, 10),
+		),
+		10,
+	)
+
+	testShutdownDrain(t, shutdown, beginner)
+}
+
+func newDrainShutdownBeginner(t *testing.T) *tx.ShutdownBeginner {
+	return tx.NewShutdownBeginner(
+		tx.BeginnerWithDriver(
+			txmocks.JoinBeginners(
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollback(nil), nil),
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil),
+			)(t),
+			txmocks.ExpectDriverError(errors.Is, io.ErrUnexpectedEOF, errors.Join(tx.ErrSerialization, io.ErrUnexpectedEOF))(t),
+		),
+	)
+}
+
+// testShutdownDrain runs transaction with beginner that wraps shutdown and shuts it down in the middle of the run,
+// the run must be retried and committed.
+func testShutdownDrain(t *testing.T, shutdown *tx.ShutdownBeginner, beginner tx.Beginner) {
+	ctx := context.Background()
+
+	inRun := make(chan struct{})
+	release := make(chan struct{})
+	runDone := make(chan error)
+
+	go func() {
+		attempt := 0
+
+		runDone <- tx.Run(ctx, beginner,
+			func(context.Context) error {
+				attempt++
+				if attempt == 1 {
+					close(inRun)
+					<-release
+
+					return io.ErrUnexpectedEOF
+				}
+
+				return nil
+			},
+			nil,
+			tx.RetrySerialization(1),
+		)
+	}()
+
+	<-inRun
+
+	shutdownDone := make(chan error)
+
+	go func() {
+		shutdownDone <- shutdown.Shutdown(ctx)
+	}()
+
+	waitShuttingDown(t, shutdown)
+
+	_, err := beginner.BeginTx(ctx, nil)
+	if !errors.Is(err, tx.ErrShuttingDown) {
+		t.Fatalf("expected shutting down error, actual %+v", err)
+	}
+
+	err = tx.Run(ctx, beginner, func(context.Context) error { return nil }, nil)
+	if !errors.Is(err, tx.ErrBeginTx) || !errors.Is(err, tx.ErrShuttingDown) {
+		t.Fatalf("expected shutting down error, actual %+v", err)
+	}
+
+	select {
+	case err := <-shutdownDone:
+		t.Fatalf("shutdown finished before in-flight run, %+v", err)
+	default:
+	}
+
+	close(release)
+
+	err = <-runDone
+	if err != nil {
+		t.Fatalf("in-flight run expected to be retried and committed, actual %+v", err)
+	}
+
+	err = <-shutdownDone
+	if err != nil {
+		t.Fatalf("unexpected shutdown error, %+v", err)
+	}
+}
+
+func Test_ShutdownBeginner_Drain_NestedRun(t *testing.T) {
+	ctx := context.Background()
+
+	beginner := tx.NewShutdownBeginner(
+		txmocks.JoinBeginners(
+			txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil),
+			txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil),
+		)(t),
+	)
+
+	inRun := make(chan struct{})
+	release := make(chan struct{})
+	runDone := make(chan error)
+
+	var nestedErr error
+
+	go func() {
+		runDone <- tx.Run(ctx, beginner,
+			func(txContext context.Context) error {
+				close(inRun)
+				<-release
+
+				nestedErr = tx.Run(txContext, beginner, func(context.Context) error { return nil }, nil)
+
+				return nil
+			},
+			nil,
+		)
+	}()
+
+	<-inRun
+
+	shutdownDone := make(chan error)
+
+	go func() {
+		shutdownDone <- beginner.Shutdown(ctx)
+	}()
+
+	waitShuttingDown(t, beginner)
+
+	err := tx.Run(ctx, beginner, func(context.Context) error { return nil }, nil)
+	if !errors.Is(err, tx.ErrShuttingDown) {
+		t.Fatalf("expected shutting down error, actual %+v", err)
+	}
+
+	close(release)
+
+	err = <-runDone
+	if err != nil {
+		t.Fatalf("unexpected run error, %+v", err)
+	}
+
+	if nestedErr != nil {
+		t.Fatalf("nested run of in-flight run expected to be let through, actual %+v", nestedErr)
+	}
+
+	err = <-shutdownDone
+	if err != nil {
+		t.Fatalf("unexpected shutdown error, %+v", err)
+	}
+}
+
+func Test_ShutdownBeginner_Force(t *testing.T) {
+	beginner := tx.NewShutdownBeginner(
+		txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollback(nil), nil)(t),
+	)
+
+	_, err := beginner.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected begin error, %+v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err = beginner.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, actual %+v", err)
+	}
+
+	_, err = beginner.BeginTx(context.Background(), nil)
+	if !errors.Is(err, tx.ErrShuttingDown) {
+		t.Fatalf("expected shutting down error, actual %+v", err)
+	}
+}
+
+func Test_ShutdownBeginner_Idle(t *testing.T) {
+	beginner := tx.NewShutdownBeginner(txmocks.ExpectNothing()(t))
+
+	err := beginner.Shutdown(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected shutdown error, %+v", err)
+	}
+}
+
+func waitShuttingDown(t *testing.T, beginner *tx.ShutdownBeginner) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for !beginner.ShuttingDown() {
+		if time.Now().After(deadline) {
+			t.Fatal("beginner is not shutting down")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -3,20 +3,22 @@ package sqltx
 import (
 	"context"
 	"database/sql"
-	"sync"
 
 	ttn "github.com/amidgo/tx"
+	"github.com/amidgo/tx/internal/leak"
+	"github.com/amidgo/tx/internal/nested"
+	"github.com/amidgo/tx/internal/sqlctx"
+	"github.com/amidgo/tx/internal/txstate"
 )
 
-type txKey struct{}
-
 var _ ttn.Tx = (*tx)(nil)
 
 type tx struct {
 	sqlTx *sql.Tx
 
-	ctx  context.Context
-	once sync.Once
+	ctx     context.Context
+	state   txstate.Machine
+	untrack func()
 }
 
 func (s *tx) Context() context.Context {
@@ -24,57 +26,97 @@ func (s *tx) Context() context.Context {
 }
 
 func (s *tx) Commit() error {
-	s.clearTx()
+	defer s.untrack()
 
-	return s.sqlTx.Commit()
+	return s.state.Commit(s.sqlTx.Commit)
 }
 
 func (s *tx) Rollback() error {
-	s.clearTx()
+	defer s.untrack()
 
-	return s.sqlTx.Rollback()
+	return s.state.Rollback(s.sqlTx.Rollback)
 }
 
-func (s *tx) clearTx() {
-	s.once.Do(func() {
-		s.ctx = context.WithValue(s.ctx, txKey{}, nil)
-	})
+func (s *tx) State() ttn.TxState {
+	return ttn.TxState(s.state.State())
 }
 
 type Beginner struct {
-	db *sql.DB
+	db      *sql.DB
+	opts    ttn.BeginnerOptions
+	tracker *leak.Tracker
 }
 
-func NewBeginner(db *sql.DB) *Beginner {
-	return &Beginner{db: db}
-}
+func NewBeginner(db *sql.DB, opts ...ttn.BeginnerOption) *Beginner {
+	options := ttn.ApplyBeginnerOptions(opts...)
 
-func (s *Beginner) Begin(ctx context.Context) (ttn.Tx, error) {
-	sqlTx, err := s.db.BeginTx(ctx, nil)
-	if err != nil {
-		return nil, err
+	return &Beginner{
+		db:      db,
+		opts:    options,
+		tracker: leak.New(options),
 	}
+}
 
-	return &tx{
-		sqlTx: sqlTx,
-		ctx:   s.txContext(ctx, sqlTx),
-	}, nil
+func (s *Beginner) Begin(ctx context.Context) (ttn.Tx, error) {
+	return s.BeginTx(ctx, nil)
 }
 
+// BeginTx begins transaction on the connection pinned by WithConn if any, on the pool otherwise,
+// ttn.ErrNestedBegin is returned if the pinned connection already holds active transaction.
 func (s *Beginner) BeginTx(ctx context.Context, opts *sql.TxOptions) (ttn.Tx, error) {
-	sqlTx, err := s.db.BeginTx(ctx, opts)
+	var beginTx func(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) = s.db.BeginTx
+
+	conn, ok := pinnedConn(ctx, s.db)
+	if ok {
+		beginTx = conn.beginTx
+	} else {
+		err := nested.CheckBegin(ctx, s.db, s.opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sqlTx, err := beginTx(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	return &tx{
-		sqlTx: sqlTx,
-		ctx:   s.txContext(ctx, sqlTx),
-	}, nil
+	stack := nested.Stack(s.opts)
+
+	tx := newTx(ctx, s.db, sqlTx, stack, s.tracker.Track(stack))
+	if conn != nil {
+		conn.hold(tx)
+	}
+
+	return tx, nil
 }
 
-func (s *Beginner) txContext(ctx context.Context, sqlTx *sql.Tx) context.Context {
-	return context.WithValue(ctx, txKey{}, sqlTx)
+func newTx(ctx context.Context, db *sql.DB, sqlTx *sql.Tx, stack []byte, untrack func()) *tx {
+	tx := &tx{sqlTx: sqlTx, untrack: untrack}
+	tx.ctx = sqlctx.WithTx(ctx,
+		&sqlctx.Tx{
+			DB:     db,
+			Tx:     sqlTx,
+			Native: sqlTx,
+			State:  &tx.state,
+			Stack:  stack,
+		},
+	)
+
+	return tx
+}
+
+// ContextWithTx places externally created sqlTx into ctx,
+// so Beginner.Executor and Beginner.TxEnabled pick it up.
+// Caller remains responsible for commit or rollback of sqlTx.
+func ContextWithTx(ctx context.Context, sqlTx *sql.Tx) context.Context {
+	return sqlctx.WithTx(ctx,
+		&sqlctx.Tx{
+			Tx:     sqlTx,
+			Native: sqlTx,
+			State:  &txstate.Machine{},
+		},
+	)
 }
 
 func (s *Beginner) Executor(ctx context.Context) Executor {
@@ -84,18 +126,33 @@ func (s *Beginner) Executor(ctx context.Context) Executor {
 }
 
 func (s *Beginner) TxEnabled(ctx context.Context) bool {
-	_, ok := s.executor(ctx)
+	_, ok := sqlctx.FromContext(ctx, s.db)
 
 	return ok
 }
 
+// OpenTransactions returns transactions that are neither committed nor rolled back,
+// transactions are tracked only if the beginner was created with ttn.TrackTransactions.
+func (s *Beginner) OpenTransactions() []ttn.OpenTransaction {
+	return s.tracker.Open()
+}
+
+// executor returns transaction began on the same *sql.DB by any of the adapters,
+// connection pinned by WithConn or the db.
 func (s *Beginner) executor(ctx context.Context) (Executor, bool) {
-	tx, ok := ctx.Value(txKey{}).(*sql.Tx)
-	if !ok {
-		return s.db, false
+	tx, ok := sqlctx.FromContext(ctx, s.db)
+	if ok {
+		return tx.Tx, true
 	}
 
-	return tx, true
+	conn, ok := pinnedConn(ctx, s.db)
+	if ok {
+		return connExecutor{Conn: conn.Conn}, false
+	}
+
+	nested.CheckExecutor(ctx, s.db, s.opts)
+
+	return s.db, false
 }
 
 func (s *Beginner) WithTx(
@@ -109,7 +166,7 @@ func (s *Beginner) WithTx(
 			exec := s.Executor(txContext)
 
 			// must be ctx without executor
-			return withTx(ctx, exec)
+			return withTx(sqlctx.WithHeld(ctx, txContext, s.db), exec)
 		},
 		txOpts,
 		opts...,
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
 	postgrescontainer "github.com/amidgo/containers/postgres"
 	"github.com/amidgo/containers/postgres/migrations"
@@ -209,3 +210,118 @@ func Test_SQLBeginner_Error(t *testing.T) {
 
 	txtest.AssertBeginError(t, ctx, beginner, nil, context.Canceled)
 }
+
+func Test_SQLBeginner_State(t *testing.T) {
+	t.Parallel()
+
+	db := postgrescontainer.ReuseForTesting(t,
+		reusable.Postgres(),
+		migrations.Nil,
+	)
+
+	beginner := sqltx.NewBeginner(db)
+
+	tx, err := beginner.Begin(context.Background())
+	require.NoError(t, err)
+
+	txtest.AssertTxDone(t, beginner, tx)
+}
+
+func Test_SQLBeginner_ContextWithTx(t *testing.T) {
+	t.Parallel()
+
+	const createUsersTableQuery = `
+		CREATE TABLE users (
+			id uuid primary key,
+			age smallint not null
+		)
+	`
+
+	db := postgrescontainer.ReuseForTesting(t,
+		reusable.Postgres(),
+		migrations.Nil,
+		createUsersTableQuery,
+	)
+
+	beginner := sqltx.NewBeginner(db)
+
+	sqlTx, err := db.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+
+	ctx := sqltx.ContextWithTx(context.Background(), sqlTx)
+
+	require.True(t, beginner.TxEnabled(ctx))
+	require.Equal(t, sqlTx, beginner.Executor(ctx))
+
+	userID := uuid.New()
+	userAge := 100
+
+	err = tx.Run(ctx, beginner,
+		func(txContext context.Context) error {
+			_, err := beginner.Executor(txContext).ExecContext(txContext, "INSERT INTO users (id, age) VALUES ($1, $2)", userID, userAge)
+
+			return err
+		},
+		nil,
+		tx.WithPropagation(tx.PropagationRequired),
+	)
+	require.NoError(t, err)
+
+	txtest.AssertUserNotFound(t, db, userID)
+	txtest.AssertUserExists(t, sqlTx, userID, userAge)
+
+	err = sqlTx.Rollback()
+	require.NoError(t, err)
+
+	txtest.AssertUserNotFound(t, db, userID)
+}
+
+func Test_SQLBeginner_NestedBegin(t *testing.T) {
+	t.Parallel()
+
+	db := postgrescontainer.ReuseForTesting(t,
+		reusable.Postgres(),
+		migrations.Nil,
+	)
+
+	db.SetMaxOpenConns(1)
+
+	beginner := sqltx.NewBeginner(db, tx.DetectNestedBegin(tx.NestedBeginFail, nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	t.Cleanup(cancel)
+
+	err := tx.Run(ctx, beginner,
+		func(txContext context.Context) error {
+			return tx.Run(txContext, beginner,
+				func(context.Context) error { return nil },
+				nil,
+			)
+		},
+		nil,
+	)
+	require.ErrorIs(t, err, tx.ErrBeginTx)
+	require.ErrorIs(t, err, tx.ErrNestedBegin)
+
+	err = beginner.WithTx(ctx,
+		func(ctx context.Context, _ sqltx.Executor) error {
+			_, err := beginner.BeginTx(ctx, nil)
+
+			return err
+		},
+		nil,
+	)
+	require.ErrorIs(t, err, tx.ErrNestedBegin)
+
+	err = tx.Run(ctx, beginner,
+		func(txContext context.Context) error {
+			return tx.Run(txContext, beginner,
+				func(context.Context) error { return nil },
+				nil,
+				tx.WithPropagation(tx.PropagationRequired),
+			)
+		},
+		nil,
+	)
+	require.NoError(t, err)
+}
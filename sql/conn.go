@@ -0,0 +1,197 @@
+package sqltx
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+
+	ttn "github.com/amidgo/tx"
+	"github.com/amidgo/tx/internal/leak"
+	"github.com/amidgo/tx/internal/nested"
+	"github.com/amidgo/tx/internal/sqlctx"
+	"github.com/amidgo/tx/internal/txstate"
+)
+
+type connKey struct{}
+
+type pinned struct {
+	db   *sql.DB
+	conn *sessionConn
+}
+
+func pinnedConn(ctx context.Context, db *sql.DB) (*sessionConn, bool) {
+	p, ok := ctx.Value(connKey{}).(pinned)
+	if !ok || p.db != db {
+		return nil, false
+	}
+
+	return p.conn, true
+}
+
+// sessionConn is a pinned connection, it holds at most one active transaction,
+// since nested BEGIN is a no-op on most of databases and nested COMMIT would commit the outer transaction.
+type sessionConn struct {
+	*sql.Conn
+	active atomic.Pointer[txstate.Machine]
+}
+
+func (s *sessionConn) beginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	state := s.active.Load()
+	if state != nil && state.Active() {
+		return nil, ttn.ErrNestedBegin
+	}
+
+	return s.Conn.BeginTx(ctx, opts)
+}
+
+func (s *sessionConn) hold(tx *tx) {
+	s.active.Store(&tx.state)
+}
+
+// WithConn pins a single connection of the pool for f,
+// Executor and transactions began with ctx passed to f use the pinned connection.
+// Begin on the pinned connection that already holds active transaction fails with tx.ErrNestedBegin.
+// Nested WithConn reuses already pinned connection.
+func (s *Beginner) WithConn(ctx context.Context, f func(ctx context.Context) error) error {
+	_, ok := pinnedConn(ctx, s.db)
+	if ok {
+		return f(ctx)
+	}
+
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	return f(context.WithValue(ctx, connKey{}, pinned{db: s.db, conn: &sessionConn{Conn: conn}}))
+}
+
+var _ ttn.Beginner = (*ConnBeginner)(nil)
+
+// ConnBeginner begins transactions on a single connection,
+// it is useful for session level state, e.g. advisory locks, temp tables or SET variables.
+type ConnBeginner struct {
+	db      *sql.DB
+	conn    *sessionConn
+	opts    ttn.BeginnerOptions
+	tracker *leak.Tracker
+}
+
+// NewConnBeginner creates ConnBeginner on conn, pool of conn is unknown,
+// so transactions are visible only to the ConnBeginner and db agnostic helpers.
+// Use Beginner.ConnBeginner to share transactions with adapters over the pool.
+func NewConnBeginner(conn *sql.Conn, opts ...ttn.BeginnerOption) *ConnBeginner {
+	options := ttn.ApplyBeginnerOptions(opts...)
+
+	return &ConnBeginner{
+		// identity of the connection transactions in context, the pool is never used for queries
+		db:      new(sql.DB),
+		conn:    &sessionConn{Conn: conn},
+		opts:    options,
+		tracker: leak.New(options),
+	}
+}
+
+// ConnBeginner creates ConnBeginner on conn taken from the pool of the beginner,
+// transactions are visible to adapters over the same pool, options and tracked transactions are shared with the beginner.
+func (s *Beginner) ConnBeginner(conn *sql.Conn) *ConnBeginner {
+	return &ConnBeginner{
+		db:      s.db,
+		conn:    &sessionConn{Conn: conn},
+		opts:    s.opts,
+		tracker: s.tracker,
+	}
+}
+
+func (c *ConnBeginner) Begin(ctx context.Context) (ttn.Tx, error) {
+	return c.BeginTx(ctx, nil)
+}
+
+// BeginTx begins transaction on the connection,
+// ttn.ErrNestedBegin is returned if the connection already holds active transaction.
+func (c *ConnBeginner) BeginTx(ctx context.Context, opts *sql.TxOptions) (ttn.Tx, error) {
+	err := nested.CheckBegin(ctx, c.db, c.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlTx, err := c.conn.beginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	stack := nested.Stack(c.opts)
+
+	tx := newTx(ctx, c.db, sqlTx, stack, c.tracker.Track(stack))
+	c.conn.hold(tx)
+
+	return tx, nil
+}
+
+// OpenTransactions returns transactions that are neither committed nor rolled back,
+// transactions are tracked only if the beginner was created with ttn.TrackTransactions.
+func (c *ConnBeginner) OpenTransactions() []ttn.OpenTransaction {
+	return c.tracker.Open()
+}
+
+func (c *ConnBeginner) Executor(ctx context.Context) Executor {
+	executor, _ := c.executor(ctx)
+
+	return executor
+}
+
+func (c *ConnBeginner) TxEnabled(ctx context.Context) bool {
+	_, ok := c.executor(ctx)
+
+	return ok
+}
+
+func (c *ConnBeginner) executor(ctx context.Context) (Executor, bool) {
+	tx, ok := sqlctx.FromContext(ctx, c.db)
+	if !ok {
+		return connExecutor{Conn: c.conn.Conn}, false
+	}
+
+	return tx.Tx, true
+}
+
+func (c *ConnBeginner) WithTx(
+	ctx context.Context,
+	withTx func(ctx context.Context, exec Executor) error,
+	txOpts *sql.TxOptions,
+	opts ...ttn.Option,
+) error {
+	return ttn.Run(ctx, c,
+		func(txContext context.Context) error {
+			exec := c.Executor(txContext)
+
+			// must be ctx without executor
+			return withTx(ctx, exec)
+		},
+		txOpts,
+		opts...,
+	)
+}
+
+// connExecutor completes *sql.Conn to Executor, methods without context use context.Background.
+type connExecutor struct {
+	*sql.Conn
+}
+
+func (c connExecutor) Exec(query string, args ...any) (sql.Result, error) {
+	return c.ExecContext(context.Background(), query, args...)
+}
+
+func (c connExecutor) Query(query string, args ...any) (*sql.Rows, error) {
+	return c.QueryContext(context.Background(), query, args...)
+}
+
+func (c connExecutor) QueryRow(query string, args ...any) *sql.Row {
+	return c.QueryRowContext(context.Background(), query, args...)
+}
+
+func (c connExecutor) Prepare(query string) (*sql.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
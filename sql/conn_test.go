@@ -0,0 +1,190 @@
+package sqltx_test
+
+import (
+	"context"
+	"testing"
+
+	postgrescontainer "github.com/amidgo/containers/postgres"
+	"github.com/amidgo/containers/postgres/migrations"
+	"github.com/amidgo/tx"
+	sqltx "github.com/amidgo/tx/sql"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amidgo/tx/internal/reusable"
+)
+
+func Test_ConnBeginner(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	db := postgrescontainer.ReuseForTesting(t,
+		reusable.Postgres(),
+		migrations.Nil,
+	)
+
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { conn.Close() })
+
+	beginner := sqltx.NewBeginner(db).ConnBeginner(conn)
+
+	require.False(t, beginner.TxEnabled(ctx))
+
+	_, err = beginner.Executor(ctx).ExecContext(ctx, "SET application_name = 'conn_beginner'")
+	require.NoError(t, err)
+
+	sessionPID := backendPID(t, beginner.Executor(ctx))
+
+	other := sqltx.NewBeginner(
+		postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil),
+	)
+
+	for range 2 {
+		err = tx.Run(ctx, beginner,
+			func(txContext context.Context) error {
+				require.True(t, beginner.TxEnabled(txContext))
+				require.True(t, sqltx.NewBeginner(db).TxEnabled(txContext))
+				require.False(t, other.TxEnabled(txContext))
+
+				exec := beginner.Executor(txContext)
+
+				require.Equal(t, sessionPID, backendPID(t, exec))
+
+				var applicationName string
+
+				err := exec.QueryRowContext(txContext, "SHOW application_name").Scan(&applicationName)
+				require.NoError(t, err)
+				require.Equal(t, "conn_beginner", applicationName)
+
+				return nil
+			},
+			nil,
+		)
+		require.NoError(t, err)
+	}
+}
+
+func Test_NewConnBeginner_Options(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	db := postgrescontainer.ReuseForTesting(t,
+		reusable.Postgres(),
+		migrations.Nil,
+	)
+
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { conn.Close() })
+
+	beginner := sqltx.NewConnBeginner(conn,
+		tx.DetectNestedBegin(tx.NestedBeginFail, nil),
+		tx.TrackTransactions(),
+	)
+
+	err = tx.Run(ctx, beginner,
+		func(txContext context.Context) error {
+			require.True(t, beginner.TxEnabled(txContext))
+			// pool of conn is unknown, the transaction isn't shared with adapters over db
+			require.False(t, sqltx.NewBeginner(db).TxEnabled(txContext))
+			require.Len(t, beginner.OpenTransactions(), 1)
+
+			_, err := beginner.Begin(txContext)
+			require.ErrorIs(t, err, tx.ErrNestedBegin)
+
+			return nil
+		},
+		nil,
+	)
+	require.NoError(t, err)
+
+	require.Empty(t, beginner.OpenTransactions())
+}
+
+func Test_SQLBeginner_WithConn(t *testing.T) {
+	t.Parallel()
+
+	db := postgrescontainer.ReuseForTesting(t,
+		reusable.Postgres(),
+		migrations.Nil,
+	)
+
+	beginner := sqltx.NewBeginner(db)
+
+	err := beginner.WithConn(context.Background(),
+		func(ctx context.Context) error {
+			require.False(t, beginner.TxEnabled(ctx))
+
+			sessionPID := backendPID(t, beginner.Executor(ctx))
+
+			return tx.Run(ctx, beginner,
+				func(txContext context.Context) error {
+					require.True(t, beginner.TxEnabled(txContext))
+					require.Equal(t, sessionPID, backendPID(t, beginner.Executor(txContext)))
+
+					return beginner.WithConn(txContext,
+						func(ctx context.Context) error {
+							require.Equal(t, sessionPID, backendPID(t, beginner.Executor(ctx)))
+
+							return nil
+						},
+					)
+				},
+				nil,
+			)
+		},
+	)
+	require.NoError(t, err)
+}
+
+func Test_SQLBeginner_WithConn_NestedRun(t *testing.T) {
+	t.Parallel()
+
+	db := postgrescontainer.ReuseForTesting(t,
+		reusable.Postgres(),
+		migrations.Nil,
+	)
+
+	beginner := sqltx.NewBeginner(db)
+
+	err := beginner.WithConn(context.Background(),
+		func(ctx context.Context) error {
+			err := tx.Run(ctx, beginner,
+				func(txContext context.Context) error {
+					return tx.Run(txContext, beginner,
+						func(context.Context) error {
+							return nil
+						},
+						nil,
+					)
+				},
+				nil,
+			)
+			require.ErrorIs(t, err, tx.ErrNestedBegin)
+
+			// outer transaction is rolled back, connection is free for the next one
+			return tx.Run(ctx, beginner,
+				func(txContext context.Context) error {
+					require.True(t, beginner.TxEnabled(txContext))
+
+					return nil
+				},
+				nil,
+			)
+		},
+	)
+	require.NoError(t, err)
+}
+
+func backendPID(t *testing.T, exec sqltx.Executor) int {
+	var pid int
+
+	err := exec.QueryRowContext(context.Background(), "SELECT pg_backend_pid()").Scan(&pid)
+	require.NoError(t, err)
+
+	return pid
+}
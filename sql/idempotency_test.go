@@ -0,0 +1,134 @@
+package sqltx_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	postgrescontainer "github.com/amidgo/containers/postgres"
+	"github.com/amidgo/containers/postgres/migrations"
+	"github.com/amidgo/tx"
+	sqltx "github.com/amidgo/tx/sql"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amidgo/tx/internal/reusable"
+	txtest "github.com/amidgo/tx/internal/testing"
+)
+
+type createUserResponse struct {
+	ID  uuid.UUID `json:"id"`
+	Age int       `json:"age"`
+}
+
+func Test_SQLRunIdempotent(t *testing.T) {
+	t.Parallel()
+
+	const createUsersTableQuery = `
+		CREATE TABLE users (
+			id uuid primary key,
+			age smallint not null
+		)
+	`
+
+	db := postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil,
+		createUsersTableQuery,
+		tx.CreateIdempotencyTableQuery,
+	)
+
+	beginner := sqltx.NewBeginner(db)
+
+	ctx := context.Background()
+
+	var created atomic.Int32
+
+	createUser := func(age int) func(txContext context.Context) (createUserResponse, error) {
+		return func(txContext context.Context) (createUserResponse, error) {
+			created.Add(1)
+
+			response := createUserResponse{ID: uuid.New(), Age: age}
+
+			_, err := beginner.Executor(txContext).ExecContext(txContext,
+				"INSERT INTO users (id, age) VALUES ($1, $2)",
+				response.ID, response.Age,
+			)
+
+			return response, err
+		}
+	}
+
+	key := tx.IdempotencyKey{Key: "create-user", Fingerprint: "age=10"}
+
+	errRun := errors.New("run failed")
+
+	_, err := tx.RunIdempotent(ctx, beginner, key,
+		func(context.Context) (createUserResponse, error) {
+			return createUserResponse{}, errRun
+		},
+		nil,
+	)
+	require.ErrorIs(t, err, errRun)
+
+	first, err := tx.RunIdempotent(ctx, beginner, key, createUser(10), nil)
+	require.NoError(t, err)
+
+	txtest.AssertUserExists(t, db, first.ID, 10)
+
+	replay, err := tx.RunIdempotent(ctx, beginner, key, createUser(10), nil)
+	require.NoError(t, err)
+	require.Equal(t, first, replay)
+	require.Equal(t, int32(1), created.Load())
+
+	_, err = tx.RunIdempotent(ctx, beginner,
+		tx.IdempotencyKey{Key: key.Key, Fingerprint: "age=20"},
+		createUser(20),
+		nil,
+	)
+	require.ErrorIs(t, err, tx.ErrIdempotencyConflict)
+	require.Equal(t, int32(1), created.Load())
+}
+
+func Test_SQLRunIdempotent_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	db := postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil, tx.CreateIdempotencyTableQuery)
+
+	beginner := sqltx.NewBeginner(db)
+
+	var (
+		runs      atomic.Int32
+		wg        sync.WaitGroup
+		responses [4]int32
+		errs      [4]error
+	)
+
+	for i := range responses {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			responses[i], errs[i] = tx.RunIdempotent(context.Background(), beginner,
+				tx.IdempotencyKey{Key: "concurrent", Fingerprint: "same"},
+				func(context.Context) (int32, error) {
+					time.Sleep(100 * time.Millisecond)
+
+					return runs.Add(1), nil
+				},
+				nil,
+			)
+		}()
+	}
+
+	wg.Wait()
+
+	require.Equal(t, int32(1), runs.Load())
+
+	for i := range responses {
+		require.NoError(t, errs[i])
+		require.Equal(t, int32(1), responses[i])
+	}
+}
@@ -0,0 +1,50 @@
+package sqltx_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	postgrescontainer "github.com/amidgo/containers/postgres"
+	"github.com/amidgo/containers/postgres/migrations"
+	"github.com/amidgo/tx"
+	sqltx "github.com/amidgo/tx/sql"
+	"github.com/amidgo/tx/txtest"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amidgo/tx/internal/reusable"
+)
+
+func Test_SQLBeginner_TrackTransactions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	db := postgrescontainer.ReuseForTesting(t,
+		reusable.Postgres(),
+		migrations.Nil,
+	)
+
+	beginner := sqltx.NewBeginner(db, tx.TrackTransactions())
+	txtest.AssertNoLeakedTransactions(t, beginner)
+
+	committed, err := beginner.Begin(ctx)
+	require.NoError(t, err)
+
+	leaked, err := beginner.Begin(ctx)
+	require.NoError(t, err)
+
+	open := beginner.OpenTransactions()
+	require.Len(t, open, 2)
+	require.True(t, bytes.Contains(open[0].Stack, []byte("Test_SQLBeginner_TrackTransactions")))
+
+	require.NoError(t, committed.Commit())
+	require.Len(t, beginner.OpenTransactions(), 1)
+
+	require.NoError(t, leaked.Rollback())
+	require.Empty(t, beginner.OpenTransactions())
+
+	err = tx.Run(ctx, beginner, func(context.Context) error { return nil }, nil)
+	require.NoError(t, err)
+	require.Empty(t, sqltx.NewBeginner(db).OpenTransactions())
+}
@@ -0,0 +1,81 @@
+package sqltx_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	postgrescontainer "github.com/amidgo/containers/postgres"
+	"github.com/amidgo/containers/postgres/migrations"
+	"github.com/amidgo/tx"
+	sqltx "github.com/amidgo/tx/sql"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amidgo/tx/internal/reusable"
+	txtest "github.com/amidgo/tx/internal/testing"
+)
+
+func Test_RunMulti_SQLBeginners(t *testing.T) {
+	t.Parallel()
+
+	const createUsersTableQuery = `
+		CREATE TABLE users (
+			id uuid primary key,
+			age smallint not null
+		)
+	`
+
+	firstDB := postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil, createUsersTableQuery)
+	secondDB := postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil, createUsersTableQuery)
+
+	first, second := sqltx.NewBeginner(firstDB), sqltx.NewBeginner(secondDB)
+
+	ctx := context.Background()
+
+	insertUser := func(txContext context.Context, userID uuid.UUID, userAge int) {
+		for _, beginner := range []*sqltx.Beginner{first, second} {
+			require.True(t, beginner.TxEnabled(txContext))
+
+			exec := beginner.Executor(txContext)
+
+			_, err := exec.ExecContext(txContext, "INSERT INTO users (id, age) VALUES ($1, $2)", userID, userAge)
+			require.NoError(t, err)
+		}
+	}
+
+	committedID, userAge := uuid.New(), 10
+
+	err := tx.RunMulti(ctx, []tx.Beginner{first, second},
+		func(txContext context.Context) error {
+			insertUser(txContext, committedID, userAge)
+
+			// helpers without db can't pick one of the transactions
+			err := tx.ExecVersioned(txContext, "UPDATE users SET age = age WHERE id = $1", committedID)
+			require.ErrorIs(t, err, tx.ErrAmbiguousTx)
+
+			return nil
+		},
+		nil,
+	)
+	require.NoError(t, err)
+
+	txtest.AssertUserExists(t, firstDB, committedID, userAge)
+	txtest.AssertUserExists(t, secondDB, committedID, userAge)
+
+	errStub := errors.New("stub err")
+	rolledBackID := uuid.New()
+
+	err = tx.RunMulti(ctx, []tx.Beginner{first, second},
+		func(txContext context.Context) error {
+			insertUser(txContext, rolledBackID, userAge)
+
+			return errStub
+		},
+		nil,
+	)
+	require.ErrorIs(t, err, errStub)
+
+	txtest.AssertUserNotFound(t, firstDB, rolledBackID)
+	txtest.AssertUserNotFound(t, secondDB, rolledBackID)
+}
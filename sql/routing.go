@@ -0,0 +1,41 @@
+package sqltx
+
+import (
+	"context"
+
+	ttn "github.com/amidgo/tx"
+)
+
+// RoutingBeginner is a ttn.RoutingBeginner over adapter beginners,
+// Executor follows the same routing for non transactional reads of ctx marked by ttn.ReadOnlyContext.
+type RoutingBeginner struct {
+	*ttn.RoutingBeginner
+
+	beginners []*Beginner
+}
+
+func NewRoutingBeginner(primary *Beginner, replicas []*Beginner, opts ...ttn.RoutingOption) *RoutingBeginner {
+	beginners := append([]*Beginner{primary}, replicas...)
+
+	replicaBeginners := make([]ttn.Beginner, len(replicas))
+	for i := range replicas {
+		replicaBeginners[i] = replicas[i]
+	}
+
+	return &RoutingBeginner{
+		RoutingBeginner: ttn.NewRoutingBeginner(primary, replicaBeginners, opts...),
+		beginners:       beginners,
+	}
+}
+
+// Executor returns transaction of ctx began on any of the beginners,
+// otherwise executor of the beginner routed by ttn.RoutingBeginner.Route.
+func (r *RoutingBeginner) Executor(ctx context.Context) Executor {
+	for _, beginner := range r.beginners {
+		if beginner.TxEnabled(ctx) {
+			return beginner.Executor(ctx)
+		}
+	}
+
+	return r.Route(ctx).(*Beginner).Executor(ctx)
+}
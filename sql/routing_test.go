@@ -0,0 +1,71 @@
+package sqltx_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	postgrescontainer "github.com/amidgo/containers/postgres"
+	"github.com/amidgo/containers/postgres/migrations"
+	"github.com/amidgo/tx"
+	sqltx "github.com/amidgo/tx/sql"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amidgo/tx/internal/reusable"
+	txtest "github.com/amidgo/tx/internal/testing"
+)
+
+func Test_SQLRoutingBeginner(t *testing.T) {
+	t.Parallel()
+
+	const createUsersTableQuery = `
+		CREATE TABLE users (
+			id uuid primary key,
+			age smallint not null
+		)
+	`
+
+	primaryDB := postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil, createUsersTableQuery)
+	replicaDB := postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil, createUsersTableQuery)
+
+	replicaUserID := uuid.New()
+	replicaUserAge := 10
+
+	_, err := replicaDB.Exec("INSERT INTO users (id, age) VALUES ($1, $2)", replicaUserID, replicaUserAge)
+	require.NoError(t, err)
+
+	beginner := sqltx.NewRoutingBeginner(
+		sqltx.NewBeginner(primaryDB),
+		[]*sqltx.Beginner{sqltx.NewBeginner(replicaDB)},
+	)
+
+	ctx := context.Background()
+
+	require.Equal(t, primaryDB, beginner.Executor(ctx))
+	require.Equal(t, replicaDB, beginner.Executor(tx.ReadOnlyContext(ctx)))
+
+	err = tx.Run(ctx, beginner,
+		func(txContext context.Context) error {
+			require.True(t, beginner.TxEnabled(txContext))
+
+			txtest.AssertUserExists(t, beginner.Executor(txContext), replicaUserID, replicaUserAge)
+
+			return nil
+		},
+		&sql.TxOptions{ReadOnly: true},
+	)
+	require.NoError(t, err)
+
+	err = tx.Run(ctx, beginner,
+		func(txContext context.Context) error {
+			require.True(t, beginner.TxEnabled(txContext))
+
+			txtest.AssertUserNotFound(t, beginner.Executor(tx.ReadOnlyContext(txContext)), replicaUserID)
+
+			return nil
+		},
+		nil,
+	)
+	require.NoError(t, err)
+}
@@ -0,0 +1,34 @@
+package sqltx
+
+import (
+	"context"
+
+	ttn "github.com/amidgo/tx"
+)
+
+// ShardedBeginner is a ttn.ShardedBeginner over adapter beginners.
+type ShardedBeginner struct {
+	*ttn.ShardedBeginner
+}
+
+func NewShardedBeginner(shards []*Beginner, shard ttn.ShardFunc) *ShardedBeginner {
+	beginners := make([]ttn.Beginner, len(shards))
+	for i := range shards {
+		beginners[i] = shards[i]
+	}
+
+	return &ShardedBeginner{
+		ShardedBeginner: ttn.NewShardedBeginner(beginners, shard),
+	}
+}
+
+// Executor returns executor of the shard picked by ttn.ShardedBeginner.Shard,
+// unlike Beginner.Executor it returns error, since the shard may not be picked, e.g. ttn.ErrCrossShard.
+func (s *ShardedBeginner) Executor(ctx context.Context) (Executor, error) {
+	beginner, err := s.Shard(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return beginner.(*Beginner).Executor(ctx), nil
+}
@@ -0,0 +1,78 @@
+package sqltx_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	postgrescontainer "github.com/amidgo/containers/postgres"
+	"github.com/amidgo/containers/postgres/migrations"
+	"github.com/amidgo/tx"
+	sqltx "github.com/amidgo/tx/sql"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amidgo/tx/internal/reusable"
+	txtest "github.com/amidgo/tx/internal/testing"
+)
+
+func Test_SQLShardedBeginner(t *testing.T) {
+	t.Parallel()
+
+	const createUsersTableQuery = `
+		CREATE TABLE users (
+			id uuid primary key,
+			age smallint not null
+		)
+	`
+
+	firstDB := postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil, createUsersTableQuery)
+	secondDB := postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil, createUsersTableQuery)
+
+	beginner := sqltx.NewShardedBeginner(
+		[]*sqltx.Beginner{sqltx.NewBeginner(firstDB), sqltx.NewBeginner(secondDB)},
+		func(key string) int {
+			index, _ := strconv.Atoi(key)
+
+			return index
+		},
+	)
+
+	ctx := context.Background()
+	firstCtx := tx.ContextWithShardKey(ctx, "0")
+	secondCtx := tx.ContextWithShardKey(ctx, "1")
+
+	_, err := beginner.Executor(ctx)
+	require.ErrorIs(t, err, tx.ErrShardKeyMissing)
+
+	exec, err := beginner.Executor(secondCtx)
+	require.NoError(t, err)
+	require.Equal(t, secondDB, exec)
+
+	userID := uuid.New()
+	userAge := 10
+
+	err = tx.Run(secondCtx, beginner,
+		func(txContext context.Context) error {
+			exec, err := beginner.Executor(txContext)
+			require.NoError(t, err)
+
+			_, err = exec.ExecContext(txContext, "INSERT INTO users (id, age) VALUES ($1, $2)", userID, userAge)
+			require.NoError(t, err)
+
+			_, err = beginner.Executor(tx.ContextWithShardKey(txContext, "0"))
+			require.ErrorIs(t, err, tx.ErrCrossShard)
+
+			return nil
+		},
+		nil,
+	)
+	require.NoError(t, err)
+
+	txtest.AssertUserExists(t, secondDB, userID, userAge)
+	txtest.AssertUserNotFound(t, firstDB, userID)
+
+	exec, err = beginner.Executor(firstCtx)
+	require.NoError(t, err)
+	require.Equal(t, firstDB, exec)
+}
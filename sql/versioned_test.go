@@ -0,0 +1,68 @@
+package sqltx_test
+
+import (
+	"context"
+	"testing"
+
+	postgrescontainer "github.com/amidgo/containers/postgres"
+	"github.com/amidgo/containers/postgres/migrations"
+	"github.com/amidgo/tx"
+	sqltx "github.com/amidgo/tx/sql"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amidgo/tx/internal/reusable"
+)
+
+func Test_SQLExecVersioned(t *testing.T) {
+	t.Parallel()
+
+	db := postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil,
+		"CREATE TABLE accounts (id int primary key, balance int not null, version int not null)",
+		"INSERT INTO accounts (id, balance, version) VALUES (1, 100, 1)",
+	)
+
+	beginner := sqltx.NewBeginner(db)
+
+	ctx := context.Background()
+
+	attempts := 0
+
+	deposit := func(txContext context.Context) error {
+		attempts++
+
+		var balance, version int
+
+		err := beginner.Executor(txContext).QueryRowContext(txContext,
+			"SELECT balance, version FROM accounts WHERE id = 1",
+		).Scan(&balance, &version)
+		require.NoError(t, err)
+
+		if attempts == 1 {
+			// concurrent writer commits between read and update
+			_, err = db.Exec("UPDATE accounts SET balance = balance + 50, version = version + 1 WHERE id = 1")
+			require.NoError(t, err)
+		}
+
+		return tx.ExecVersioned(txContext,
+			"UPDATE accounts SET balance = $1, version = version + 1 WHERE id = 1 AND version = $2",
+			balance+10,
+			version,
+		)
+	}
+
+	err := tx.Run(ctx, beginner, deposit, nil)
+	require.ErrorIs(t, err, tx.ErrConflict)
+
+	attempts = 0
+
+	err = tx.Run(ctx, beginner, deposit, nil, tx.RetryConflicts(1))
+	require.NoError(t, err)
+	require.Equal(t, 2, attempts)
+
+	var balance, version int
+
+	err = db.QueryRow("SELECT balance, version FROM accounts WHERE id = 1").Scan(&balance, &version)
+	require.NoError(t, err)
+	require.Equal(t, 210, balance)
+	require.Equal(t, 4, version)
+}
@@ -3,21 +3,23 @@ package sqlxtx
 import (
 	"context"
 	"database/sql"
-	"sync"
 
 	ttn "github.com/amidgo/tx"
+	"github.com/amidgo/tx/internal/leak"
+	"github.com/amidgo/tx/internal/nested"
+	"github.com/amidgo/tx/internal/sqlctx"
+	"github.com/amidgo/tx/internal/txstate"
 	"github.com/jmoiron/sqlx"
 )
 
-type txKey struct{}
-
 var _ ttn.Tx = (*tx)(nil)
 
 type tx struct {
 	sqlxTx *sqlx.Tx
 
-	ctx  context.Context
-	once sync.Once
+	ctx     context.Context
+	state   txstate.Machine
+	untrack func()
 }
 
 func (s *tx) Context() context.Context {
@@ -25,59 +27,93 @@ func (s *tx) Context() context.Context {
 }
 
 func (s *tx) Commit() error {
-	s.clearTx()
+	defer s.untrack()
 
-	return s.sqlxTx.Commit()
+	return s.state.Commit(s.sqlxTx.Commit)
 }
 
 func (s *tx) Rollback() error {
-	s.clearTx()
+	defer s.untrack()
 
-	return s.sqlxTx.Rollback()
+	return s.state.Rollback(s.sqlxTx.Rollback)
 }
 
-func (s *tx) clearTx() {
-	s.once.Do(func() {
-		s.ctx = context.WithValue(s.ctx, txKey{}, nil)
-	})
+func (s *tx) State() ttn.TxState {
+	return ttn.TxState(s.state.State())
 }
 
 type Beginner struct {
-	db *sqlx.DB
+	db      *sqlx.DB
+	opts    ttn.BeginnerOptions
+	tracker *leak.Tracker
 }
 
-func NewBeginner(db *sqlx.DB) *Beginner {
+func NewBeginner(db *sqlx.DB, opts ...ttn.BeginnerOption) *Beginner {
+	options := ttn.ApplyBeginnerOptions(opts...)
+
 	return &Beginner{
-		db: db,
+		db:      db,
+		opts:    options,
+		tracker: leak.New(options),
 	}
 }
 
 func (s *Beginner) Begin(ctx context.Context) (ttn.Tx, error) {
+	err := nested.CheckBegin(ctx, s.db.DB, s.opts)
+	if err != nil {
+		return nil, err
+	}
+
 	sqlxTx, err := s.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelDefault, ReadOnly: false})
 	if err != nil {
 		return nil, err
 	}
 
-	return &tx{
-		sqlxTx: sqlxTx,
-		ctx:    s.txContext(ctx, sqlxTx),
-	}, nil
+	return s.newTx(ctx, sqlxTx), nil
 }
 
 func (s *Beginner) BeginTx(ctx context.Context, opts *sql.TxOptions) (ttn.Tx, error) {
+	err := nested.CheckBegin(ctx, s.db.DB, s.opts)
+	if err != nil {
+		return nil, err
+	}
+
 	sqlxTx, err := s.db.BeginTxx(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	return &tx{
-		sqlxTx: sqlxTx,
-		ctx:    s.txContext(ctx, sqlxTx),
-	}, nil
+	return s.newTx(ctx, sqlxTx), nil
+}
+
+func (s *Beginner) newTx(ctx context.Context, sqlxTx *sqlx.Tx) *tx {
+	stack := nested.Stack(s.opts)
+
+	tx := &tx{sqlxTx: sqlxTx, untrack: s.tracker.Track(stack)}
+	tx.ctx = sqlctx.WithTx(ctx,
+		&sqlctx.Tx{
+			DB:     s.db.DB,
+			Tx:     sqlxTx.Tx,
+			Native: sqlxTx,
+			State:  &tx.state,
+			Stack:  stack,
+		},
+	)
+
+	return tx
 }
 
-func (s *Beginner) txContext(ctx context.Context, tx *sqlx.Tx) context.Context {
-	return context.WithValue(ctx, txKey{}, tx)
+// ContextWithTx places externally created sqlxTx into ctx,
+// so Beginner.Executor and Beginner.TxEnabled pick it up.
+// Caller remains responsible for commit or rollback of sqlxTx.
+func ContextWithTx(ctx context.Context, sqlxTx *sqlx.Tx) context.Context {
+	return sqlctx.WithTx(ctx,
+		&sqlctx.Tx{
+			Tx:     sqlxTx.Tx,
+			Native: sqlxTx,
+			State:  &txstate.Machine{},
+		},
+	)
 }
 
 func (s *Beginner) Executor(ctx context.Context) Executor {
@@ -87,18 +123,33 @@ func (s *Beginner) Executor(ctx context.Context) Executor {
 }
 
 func (s *Beginner) TxEnabled(ctx context.Context) bool {
-	_, ok := s.executor(ctx)
+	_, ok := sqlctx.FromContext(ctx, s.db.DB)
 
 	return ok
 }
 
+// OpenTransactions returns transactions that are neither committed nor rolled back,
+// transactions are tracked only if the beginner was created with ttn.TrackTransactions.
+func (s *Beginner) OpenTransactions() []ttn.OpenTransaction {
+	return s.tracker.Open()
+}
+
+// executor returns transaction began on the same *sql.DB by any of the adapters,
+// transaction began by another adapter is wrapped into view.
 func (s *Beginner) executor(ctx context.Context) (Executor, bool) {
-	tx, ok := ctx.Value(txKey{}).(*sqlx.Tx)
+	tx, ok := sqlctx.FromContext(ctx, s.db.DB)
 	if !ok {
+		nested.CheckExecutor(ctx, s.db.DB, s.opts)
+
 		return s.db, false
 	}
 
-	return tx, true
+	sqlxTx, ok := tx.Native.(*sqlx.Tx)
+	if ok {
+		return sqlxTx, true
+	}
+
+	return newTxView(s.db, tx.Tx), true
 }
 
 func (s *Beginner) WithTx(
@@ -112,7 +163,7 @@ func (s *Beginner) WithTx(
 			exec := s.Executor(txContext)
 
 			// must be ctx without executor
-			return withTx(ctx, exec)
+			return withTx(sqlctx.WithHeld(ctx, txContext, s.db.DB), exec)
 		},
 		txOpts,
 		opts...,
@@ -207,3 +207,72 @@ func Test_SqlxBeginner_Error(t *testing.T) {
 
 	txtest.AssertBeginError(t, ctx, beginner, nil, context.Canceled)
 }
+
+func Test_SqlxBeginner_State(t *testing.T) {
+	t.Parallel()
+
+	db := postgrescontainer.ReuseForTesting(t,
+		reusable.Postgres(),
+		migrations.Nil,
+	)
+
+	sqlxDB := sqlx.NewDb(db, "pgx")
+
+	beginner := sqlxtx.NewBeginner(sqlxDB)
+
+	tx, err := beginner.Begin(context.Background())
+	require.NoError(t, err)
+
+	txtest.AssertTxDone(t, beginner, tx)
+}
+
+func Test_SqlxBeginner_ContextWithTx(t *testing.T) {
+	t.Parallel()
+
+	const createUsersTableQuery = `
+		CREATE TABLE users (
+			id uuid primary key,
+			age smallint not null
+		)
+	`
+
+	db := postgrescontainer.ReuseForTesting(t,
+		reusable.Postgres(),
+		migrations.Nil,
+		createUsersTableQuery,
+	)
+
+	sqlxDB := sqlx.NewDb(db, "pgx")
+
+	beginner := sqlxtx.NewBeginner(sqlxDB)
+
+	sqlxTx, err := sqlxDB.BeginTxx(context.Background(), nil)
+	require.NoError(t, err)
+
+	ctx := sqlxtx.ContextWithTx(context.Background(), sqlxTx)
+
+	require.True(t, beginner.TxEnabled(ctx))
+	require.Equal(t, sqlxTx, beginner.Executor(ctx))
+
+	userID := uuid.New()
+	userAge := 100
+
+	err = tx.Run(ctx, beginner,
+		func(txContext context.Context) error {
+			_, err := beginner.Executor(txContext).ExecContext(txContext, "INSERT INTO users (id, age) VALUES ($1, $2)", userID, userAge)
+
+			return err
+		},
+		nil,
+		tx.WithPropagation(tx.PropagationRequired),
+	)
+	require.NoError(t, err)
+
+	txtest.AssertUserNotFound(t, sqlxDB, userID)
+	txtest.AssertUserExists(t, sqlxTx, userID, userAge)
+
+	err = sqlxTx.Rollback()
+	require.NoError(t, err)
+
+	txtest.AssertUserNotFound(t, sqlxDB, userID)
+}
@@ -0,0 +1,71 @@
+package sqlxtx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var ErrPrepareNamedOnViewTx = errors.New("sqlxtx: named statement can't be prepared on transaction began by another adapter")
+
+// txView is a sqlx view over *sql.Tx began by another adapter on the same *sql.DB.
+// sqlx.Tx keeps driver name unexported, so bind type dependent methods are delegated to the db.
+type txView struct {
+	*sqlx.Tx
+	db *sqlx.DB
+}
+
+var _ Executor = txView{}
+
+func newTxView(db *sqlx.DB, sqlTx *sql.Tx) txView {
+	return txView{
+		Tx: &sqlx.Tx{Tx: sqlTx, Mapper: db.Mapper},
+		db: db,
+	}
+}
+
+func (v txView) DriverName() string {
+	return v.db.DriverName()
+}
+
+func (v txView) Rebind(query string) string {
+	return v.db.Rebind(query)
+}
+
+func (v txView) BindNamed(query string, arg interface{}) (string, []interface{}, error) {
+	return v.db.BindNamed(query, arg)
+}
+
+func (v txView) NamedExec(query string, arg interface{}) (sql.Result, error) {
+	return v.NamedExecContext(context.Background(), query, arg)
+}
+
+func (v txView) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	query, args, err := v.BindNamed(query, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.ExecContext(ctx, query, args...)
+}
+
+func (v txView) NamedQuery(query string, arg interface{}) (*sqlx.Rows, error) {
+	query, args, err := v.BindNamed(query, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.Queryx(query, args...)
+}
+
+func (v txView) PrepareNamed(query string) (*sqlx.NamedStmt, error) {
+	return v.PrepareNamedContext(context.Background(), query)
+}
+
+// PrepareNamedContext fails with ErrPrepareNamedOnViewTx, sqlx doesn't export named query compilation
+// and preparing on the db would acquire another pool connection while the transaction is held.
+func (v txView) PrepareNamedContext(context.Context, string) (*sqlx.NamedStmt, error) {
+	return nil, ErrPrepareNamedOnViewTx
+}
@@ -0,0 +1,101 @@
+package sqlxtx_test
+
+import (
+	"context"
+	"testing"
+
+	postgrescontainer "github.com/amidgo/containers/postgres"
+	"github.com/amidgo/containers/postgres/migrations"
+	"github.com/amidgo/tx"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amidgo/tx/internal/reusable"
+	txtest "github.com/amidgo/tx/internal/testing"
+
+	sqltx "github.com/amidgo/tx/sql"
+	sqlxtx "github.com/amidgo/tx/sqlx"
+)
+
+func Test_SqlxBeginner_SharedTx(t *testing.T) {
+	t.Parallel()
+
+	const createUsersTableQuery = `
+		CREATE TABLE users (
+			id uuid primary key,
+			age smallint not null
+		)
+	`
+
+	db := postgrescontainer.ReuseForTesting(t,
+		reusable.Postgres(),
+		migrations.Nil,
+		createUsersTableQuery,
+	)
+
+	sqlBeginner := sqltx.NewBeginner(db)
+	sqlxBeginner := sqlxtx.NewBeginner(sqlx.NewDb(db, "pgx"))
+
+	sqlUserID, sqlxUserID := uuid.New(), uuid.New()
+	userAge := 100
+
+	err := tx.Run(context.Background(), sqlBeginner,
+		func(txContext context.Context) error {
+			require.True(t, sqlxBeginner.TxEnabled(txContext))
+
+			_, err := sqlBeginner.Executor(txContext).ExecContext(txContext,
+				"INSERT INTO users (id, age) VALUES ($1, $2)",
+				sqlUserID, userAge,
+			)
+			require.NoError(t, err)
+
+			exec := sqlxBeginner.Executor(txContext)
+
+			_, err = exec.NamedExecContext(txContext,
+				"INSERT INTO users (id, age) VALUES (:id, :age)",
+				map[string]any{"id": sqlxUserID, "age": userAge},
+			)
+			require.NoError(t, err)
+
+			var count int
+
+			err = exec.GetContext(txContext, &count, exec.Rebind("SELECT count(*) FROM users WHERE id IN (?, ?)"), sqlUserID, sqlxUserID)
+			require.NoError(t, err)
+			require.Equal(t, 2, count)
+
+			_, err = exec.PrepareNamedContext(txContext, "SELECT age FROM users WHERE id = :id")
+			require.ErrorIs(t, err, sqlxtx.ErrPrepareNamedOnViewTx)
+
+			txtest.AssertUserNotFound(t, db, sqlUserID)
+			txtest.AssertUserNotFound(t, db, sqlxUserID)
+
+			return nil
+		},
+		nil,
+	)
+	require.NoError(t, err)
+
+	txtest.AssertUserExists(t, db, sqlUserID, userAge)
+	txtest.AssertUserExists(t, db, sqlxUserID, userAge)
+}
+
+func Test_SqlxBeginner_SharedTx_OtherDB(t *testing.T) {
+	t.Parallel()
+
+	db := postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil)
+	otherDB := postgrescontainer.ReuseForTesting(t, reusable.Postgres(), migrations.Nil)
+
+	sqlBeginner := sqltx.NewBeginner(db)
+	sqlxBeginner := sqlxtx.NewBeginner(sqlx.NewDb(otherDB, "pgx"))
+
+	err := tx.Run(context.Background(), sqlBeginner,
+		func(txContext context.Context) error {
+			require.False(t, sqlxBeginner.TxEnabled(txContext))
+
+			return nil
+		},
+		nil,
+	)
+	require.NoError(t, err)
+}
@@ -0,0 +1,46 @@
+package tx
+
+import "database/sql"
+
+// ErrTxDone is returned by Commit or Rollback of transaction that has already been
+// committed or rolled back, it is the same error as sql.ErrTxDone.
+var ErrTxDone = sql.ErrTxDone
+
+type TxState int32
+
+const (
+	StateUnknown TxState = iota - 1
+	StateActive
+	StateCommitting
+	StateCommitted
+	StateRolledBack
+	StateFailed
+)
+
+func (s TxState) String() string {
+	switch s {
+	case StateActive:
+		return "active"
+	case StateCommitting:
+		return "committing"
+	case StateCommitted:
+		return "committed"
+	case StateRolledBack:
+		return "rolled back"
+	case StateFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// State returns current lifecycle state of tx,
+// StateUnknown is returned if tx doesn't track its state.
+func State(tx CommitRollbacker) TxState {
+	stater, ok := tx.(interface{ State() TxState })
+	if !ok {
+		return StateUnknown
+	}
+
+	return stater.State()
+}
@@ -0,0 +1,102 @@
+package tx_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/amidgo/tx"
+	txmocks "github.com/amidgo/tx/mocks"
+)
+
+func Test_State(t *testing.T) {
+	mockTx := txmocks.ExpectCommit(t)
+
+	requireState(t, tx.StateActive, mockTx)
+
+	err := mockTx.Commit()
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+
+	requireState(t, tx.StateCommitted, mockTx)
+	requireState(t, tx.StateCommitted, tx.TxWithDriver(mockTx, nil))
+	requireState(t, tx.StateCommitted, tx.CommitRollbackerWithDriver(mockTx, nil))
+}
+
+func Test_State_Unknown(t *testing.T) {
+	requireState(t, tx.StateUnknown, commitRollbackerStub{})
+	requireState(t, tx.StateUnknown, tx.TxWithDriver(nil, nil))
+}
+
+func Test_State_Failed(t *testing.T) {
+	errCommit := errors.New("commit")
+
+	mockTx := txmocks.ExpectRollbackAfterFailedCommit(errCommit)(t)
+
+	err := mockTx.Commit()
+	if !errors.Is(err, errCommit) {
+		t.Fatalf("unexpected error, expected %s, actual %s", errCommit, err)
+	}
+
+	requireState(t, tx.StateFailed, mockTx)
+
+	err = mockTx.Rollback()
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+
+	requireState(t, tx.StateRolledBack, mockTx)
+}
+
+func Test_State_ConcurrentContext(t *testing.T) {
+	beginner := txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil)(t)
+
+	mockTx, err := beginner.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+
+	var wg sync.WaitGroup
+
+	for range 16 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for range 100 {
+				_ = beginner.TxEnabled(mockTx.Context())
+				_ = tx.State(mockTx)
+			}
+		}()
+	}
+
+	err = mockTx.Commit()
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+
+	wg.Wait()
+
+	requireState(t, tx.StateCommitted, mockTx)
+
+	if beginner.TxEnabled(mockTx.Context()) {
+		t.Fatal("tx context must not be enabled after commit")
+	}
+}
+
+type commitRollbackerStub struct{}
+
+func (commitRollbackerStub) Commit() error   { return nil }
+func (commitRollbackerStub) Rollback() error { return nil }
+
+func requireState(t *testing.T, expected tx.TxState, actual tx.CommitRollbacker) {
+	t.Helper()
+
+	state := tx.State(actual)
+	if state != expected {
+		t.Fatalf("unexpected tx state, expected %s, actual %s", expected, state)
+	}
+}
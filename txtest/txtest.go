@@ -0,0 +1,27 @@
+// Package txtest provides test helpers for users of tx.
+package txtest
+
+import (
+	"testing"
+
+	"github.com/amidgo/tx"
+)
+
+// Tracker reports open transactions, adapter beginners created with tx.TrackTransactions implement it.
+type Tracker interface {
+	OpenTransactions() []tx.OpenTransaction
+}
+
+// AssertNoLeakedTransactions fails t on cleanup if transactions of tracker are left open,
+// every leaked transaction is reported with its age and begin stack.
+func AssertNoLeakedTransactions(t testing.TB, tracker Tracker) {
+	t.Helper()
+
+	t.Cleanup(func() {
+		t.Helper()
+
+		for _, open := range tracker.OpenTransactions() {
+			t.Errorf("transaction leaked, open for %s, began at\n%s", open.Age, open.Stack)
+		}
+	})
+}
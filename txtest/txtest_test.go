@@ -0,0 +1,68 @@
+package txtest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/amidgo/tx"
+	"github.com/amidgo/tx/txtest"
+)
+
+type trackerStub []tx.OpenTransaction
+
+func (t trackerStub) OpenTransactions() []tx.OpenTransaction {
+	return t
+}
+
+type reporterStub struct {
+	testing.TB
+	cleanups []func()
+	errors   int
+}
+
+func (r *reporterStub) Helper() {}
+
+func (r *reporterStub) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
+
+func (r *reporterStub) Errorf(string, ...any) {
+	r.errors++
+}
+
+func (r *reporterStub) cleanup() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+func Test_AssertNoLeakedTransactions(t *testing.T) {
+	t.Parallel()
+
+	reporter := &reporterStub{}
+
+	txtest.AssertNoLeakedTransactions(reporter, trackerStub{
+		{Stack: []byte("first"), BeganAt: time.Now(), Age: time.Second},
+		{Stack: []byte("second"), BeganAt: time.Now(), Age: time.Second},
+	})
+
+	if reporter.errors != 0 {
+		t.Fatal("leaks must be checked on cleanup")
+	}
+
+	reporter.cleanup()
+
+	if reporter.errors != 2 {
+		t.Fatalf("expected every leaked transaction reported, reported %d", reporter.errors)
+	}
+
+	reporter = &reporterStub{}
+
+	txtest.AssertNoLeakedTransactions(reporter, trackerStub{})
+
+	reporter.cleanup()
+
+	if reporter.errors != 0 {
+		t.Fatalf("unexpected leaks reported, %d", reporter.errors)
+	}
+}
@@ -0,0 +1,45 @@
+package tx
+
+import (
+	"context"
+	"errors"
+
+	"github.com/amidgo/tx/internal/sqlctx"
+)
+
+var (
+	// ErrConflict is returned when optimistic update found no row of the expected version.
+	ErrConflict         = errors.New("optimistic concurrency conflict")
+	ErrVersionedNoSQLTx = errors.New("versioned update requires transaction of database/sql based beginner")
+)
+
+// ExecVersioned executes optimistic update, e.g. "UPDATE ... SET ..., version = version + 1 WHERE id = $1 AND version = $2",
+// in the transaction of txContext and returns ErrConflict if no row was affected.
+// Use RetryConflicts option of Run to re-run the whole transaction on conflict.
+// ErrAmbiguousTx is returned if txContext holds transactions of several databases.
+func ExecVersioned(txContext context.Context, query string, args ...any) error {
+	tx, err := sqlctx.Current(txContext)
+	if errors.Is(err, sqlctx.ErrNoTx) {
+		return ErrVersionedNoSQLTx
+	}
+
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Tx.ExecContext(txContext, query, args...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrConflict
+	}
+
+	return nil
+}
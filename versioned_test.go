@@ -0,0 +1,29 @@
+package tx_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/amidgo/tx"
+	"github.com/amidgo/tx/internal/sqlctx"
+	"github.com/amidgo/tx/internal/txstate"
+)
+
+func Test_ExecVersioned_AmbiguousTx(t *testing.T) {
+	ctx := sqlctx.WithTx(
+		sqlctx.WithTx(context.Background(), &sqlctx.Tx{DB: &sql.DB{}, State: &txstate.Machine{}}),
+		&sqlctx.Tx{DB: &sql.DB{}, State: &txstate.Machine{}},
+	)
+
+	err := tx.ExecVersioned(ctx, "UPDATE users SET version = version + 1 WHERE id = $1 AND version = $2", 1, 1)
+	if !errors.Is(err, tx.ErrAmbiguousTx) {
+		t.Fatalf("expected ambiguous tx error, actual %+v", err)
+	}
+
+	err = tx.ExecVersioned(context.Background(), "UPDATE users SET version = version + 1 WHERE id = $1 AND version = $2", 1, 1)
+	if !errors.Is(err, tx.ErrVersionedNoSQLTx) {
+		t.Fatalf("expected no tx error, actual %+v", err)
+	}
+}
@@ -0,0 +1,201 @@
+package tx
+
+import (
+	"context"
+	"database/sql"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+type (
+	txNameKey  struct{}
+	attemptKey struct{}
+)
+
+// ContextWithTxName names transactions begun with ctx, the name is reported by WatchdogBeginner.
+func ContextWithTxName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, txNameKey{}, name)
+}
+
+func TxNameFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(txNameKey{}).(string)
+
+	return name
+}
+
+// AttemptFromContext returns attempt of Run the ctx belongs to, attempts start from 1,
+// 0 is returned for ctx outside of Run.
+func AttemptFromContext(ctx context.Context) int {
+	attempt, _ := ctx.Value(attemptKey{}).(int)
+
+	return attempt
+}
+
+func contextWithAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+// LongTransaction is a transaction open longer than WatchdogBeginner threshold.
+type LongTransaction struct {
+	// Name is set by ContextWithTxName.
+	Name string
+	// Stack is a stack of the transaction begin, it is captured only with WithBeginStack.
+	Stack []byte
+	// Attempt is attempt of Run the transaction belongs to, 0 if it began outside of Run.
+	Attempt int
+	BeganAt time.Time
+	Age     time.Duration
+	// Cancel cancels the transaction context, so the transaction is rolled back by database/sql.
+	Cancel context.CancelFunc
+}
+
+type WatchdogOption func(*WatchdogBeginner)
+
+// WithBeginStack captures stack of every begin for LongTransaction.Stack,
+// capture is expensive, so it is disabled by default.
+func WithBeginStack() WatchdogOption {
+	return func(w *WatchdogBeginner) {
+		w.beginStack = true
+	}
+}
+
+// WithHardLimit cancels context of transactions open longer than limit.
+func WithHardLimit(limit time.Duration) WatchdogOption {
+	return func(w *WatchdogBeginner) {
+		w.hardLimit = limit
+	}
+}
+
+var _ Beginner = (*WatchdogBeginner)(nil)
+
+// WatchdogBeginner calls onLong once for every transaction open longer than threshold,
+// onLong is called in its own goroutine and may log, record metric or cancel the transaction.
+// Context of the transaction is cancelled when the transaction ends.
+type WatchdogBeginner struct {
+	beginner   Beginner
+	threshold  time.Duration
+	onLong     func(long LongTransaction)
+	hardLimit  time.Duration
+	beginStack bool
+}
+
+func NewWatchdogBeginner(
+	beginner Beginner,
+	threshold time.Duration,
+	onLong func(long LongTransaction),
+	opts ...WatchdogOption,
+) *WatchdogBeginner {
+	w := &WatchdogBeginner{
+		beginner:  beginner,
+		threshold: threshold,
+		onLong:    onLong,
+	}
+
+	for _, op := range opts {
+		op(w)
+	}
+
+	return w
+}
+
+func (w *WatchdogBeginner) Begin(ctx context.Context) (Tx, error) {
+	return w.begin(ctx, w.beginner.Begin)
+}
+
+func (w *WatchdogBeginner) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	return w.begin(ctx, func(ctx context.Context) (Tx, error) {
+		return w.beginner.BeginTx(ctx, opts)
+	})
+}
+
+func (w *WatchdogBeginner) begin(ctx context.Context, begin func(ctx context.Context) (Tx, error)) (Tx, error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	tx, err := begin(ctx)
+	if err != nil {
+		cancel()
+
+		return nil, err
+	}
+
+	long := LongTransaction{
+		Name:    TxNameFromContext(ctx),
+		Attempt: AttemptFromContext(ctx),
+		BeganAt: time.Now(),
+		Cancel:  cancel,
+	}
+
+	if w.beginStack {
+		long.Stack = debug.Stack()
+	}
+
+	wtx := &watchdogTx{Tx: tx, cancel: cancel}
+
+	wtx.timers = append(wtx.timers, time.AfterFunc(w.threshold, func() {
+		long.Age = time.Since(long.BeganAt)
+
+		w.onLong(long)
+	}))
+
+	if w.hardLimit > 0 {
+		wtx.timers = append(wtx.timers, time.AfterFunc(w.hardLimit, cancel))
+	}
+
+	return wtx, nil
+}
+
+func (w *WatchdogBeginner) Driver() Driver {
+	driver, _ := getDriver(w.beginner)
+
+	return driver
+}
+
+func (w *WatchdogBeginner) RetryBudget() *RetryBudget {
+	return getRetryBudget(w.beginner)
+}
+
+func (w *WatchdogBeginner) TxEnabled(ctx context.Context) bool {
+	return txEnabled(ctx, w.beginner)
+}
+
+func (w *WatchdogBeginner) tracker() (runTracker, bool) {
+	return getRunTracker(w.beginner)
+}
+
+type watchdogTx struct {
+	Tx
+	timers []*time.Timer
+	cancel context.CancelFunc
+	once   sync.Once
+}
+
+func (w *watchdogTx) Commit() error {
+	defer w.once.Do(w.stop)
+
+	return w.Tx.Commit()
+}
+
+func (w *watchdogTx) Rollback() error {
+	defer w.once.Do(w.stop)
+
+	return w.Tx.Rollback()
+}
+
+func (w *watchdogTx) stop() {
+	for _, timer := range w.timers {
+		timer.Stop()
+	}
+
+	w.cancel()
+}
+
+func (w *watchdogTx) State() TxState {
+	return State(w.Tx)
+}
+
+func (w *watchdogTx) Driver() Driver {
+	driver, _ := getDriver(w.Tx)
+
+	return driver
+}
@@ -0,0 +1,134 @@
+package tx_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/amidgo/tx"
+	txmocks "github.com/amidgo/tx/mocks"
+)
+
+func Test_WatchdogBeginner(t *testing.T) {
+	longs := make(chan tx.LongTransaction, 1)
+
+	beginner := tx.NewWatchdogBeginner(
+		tx.BeginnerWithDriver(
+			txmocks.JoinBeginners(
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollback(nil), nil),
+				txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil),
+			)(t),
+			txmocks.ExpectDriverError(errors.Is, io.ErrUnexpectedEOF, errors.Join(tx.ErrSerialization, io.ErrUnexpectedEOF))(t),
+		),
+		10*time.Millisecond,
+		func(long tx.LongTransaction) { longs <- long },
+	)
+
+	ctx := tx.ContextWithTxName(context.Background(), "transfer")
+
+	err := tx.Run(ctx, beginner,
+		func(txContext context.Context) error {
+			if tx.AttemptFromContext(txContext) == 1 {
+				return io.ErrUnexpectedEOF
+			}
+
+			long := <-longs
+			if long.Attempt != 2 {
+				t.Errorf("unexpected attempt of long transaction, %d", long.Attempt)
+			}
+
+			if long.Stack != nil {
+				t.Errorf("begin stack captured without WithBeginStack, %s", long.Stack)
+			}
+
+			return nil
+		},
+		nil,
+		tx.RetrySerialization(1),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error, %+v", err)
+	}
+
+	select {
+	case long := <-longs:
+		t.Fatalf("callback expected once per long transaction, %+v", long)
+	case <-time.After(30 * time.Millisecond):
+	}
+}
+
+func Test_WatchdogBeginner_Report(t *testing.T) {
+	longs := make(chan tx.LongTransaction, 1)
+
+	beginner := tx.NewWatchdogBeginner(
+		txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil)(t),
+		10*time.Millisecond,
+		func(long tx.LongTransaction) { longs <- long },
+		tx.WithBeginStack(),
+	)
+
+	ctx := tx.ContextWithTxName(context.Background(), "transfer")
+
+	var long tx.LongTransaction
+
+	err := tx.Run(ctx, beginner,
+		func(context.Context) error {
+			long = <-longs
+
+			return nil
+		},
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error, %+v", err)
+	}
+
+	if long.Name != "transfer" || long.Attempt != 1 || long.Age < 10*time.Millisecond {
+		t.Fatalf("unexpected long transaction, %+v", long)
+	}
+
+	if !bytes.Contains(long.Stack, []byte("Test_WatchdogBeginner_Report")) {
+		t.Fatalf("begin stack expected, actual %s", long.Stack)
+	}
+}
+
+func Test_WatchdogBeginner_HardLimit(t *testing.T) {
+	beginner := tx.NewWatchdogBeginner(
+		txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectRollback(nil), nil)(t),
+		time.Millisecond,
+		func(tx.LongTransaction) {},
+		tx.WithHardLimit(10*time.Millisecond),
+	)
+
+	err := tx.Run(context.Background(), beginner,
+		func(txContext context.Context) error {
+			<-txContext.Done()
+
+			return txContext.Err()
+		},
+		nil,
+	)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancelled transaction, actual %+v", err)
+	}
+}
+
+func Test_WatchdogBeginner_Short(t *testing.T) {
+	beginner := tx.NewWatchdogBeginner(
+		txmocks.ExpectBeginTxAndReturnTx(txmocks.ExpectCommit, nil)(t),
+		20*time.Millisecond,
+		func(long tx.LongTransaction) {
+			t.Errorf("unexpected long transaction, %+v", long)
+		},
+	)
+
+	err := tx.Run(context.Background(), beginner, func(context.Context) error { return nil }, nil)
+	if err != nil {
+		t.Fatalf("unexpected error, %+v", err)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+}